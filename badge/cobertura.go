@@ -0,0 +1,121 @@
+package badge
+
+import (
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// CoberturaOptions are the options of Profile.WriteCobertura.
+type CoberturaOptions struct {
+	// Module is the module path, stripped from the file paths so that they
+	// are relative to Source (i.e. the module root directory).
+	Module    string
+	Source    string
+	Timestamp time.Time
+}
+
+type coberturaCoverage struct {
+	XMLName         xml.Name           `xml:"coverage"`
+	LineRate        float64            `xml:"line-rate,attr"`
+	BranchRate      float64            `xml:"branch-rate,attr"`
+	LinesCovered    int                `xml:"lines-covered,attr"`
+	LinesValid      int                `xml:"lines-valid,attr"`
+	BranchesCovered int                `xml:"branches-covered,attr"`
+	BranchesValid   int                `xml:"branches-valid,attr"`
+	Complexity      float64            `xml:"complexity,attr"`
+	Version         string             `xml:"version,attr"`
+	Timestamp       int64              `xml:"timestamp,attr"`
+	Sources         []string           `xml:"sources>source"`
+	Packages        []coberturaPackage `xml:"packages>package"`
+}
+
+type coberturaPackage struct {
+	Name       string           `xml:"name,attr"`
+	LineRate   float64          `xml:"line-rate,attr"`
+	BranchRate float64          `xml:"branch-rate,attr"`
+	Complexity float64          `xml:"complexity,attr"`
+	Classes    []coberturaClass `xml:"classes>class"`
+}
+
+type coberturaClass struct {
+	Name       string          `xml:"name,attr"`
+	Filename   string          `xml:"filename,attr"`
+	LineRate   float64         `xml:"line-rate,attr"`
+	BranchRate float64         `xml:"branch-rate,attr"`
+	Complexity float64         `xml:"complexity,attr"`
+	Methods    struct{}        `xml:"methods"`
+	Lines      []coberturaLine `xml:"lines>line"`
+}
+
+type coberturaLine struct {
+	Number int `xml:"number,attr"`
+	Hits   int `xml:"hits,attr"`
+}
+
+// WriteCobertura writes the profile as a Cobertura XML coverage report (as
+// used by GitLab to annotate the merge request diffs), with a class per file
+// and the hits of each line spanned by the profile blocks.
+func (p *Profile) WriteCobertura(w io.Writer, opts CoberturaOptions) error {
+	report := coberturaCoverage{Timestamp: opts.Timestamp.UnixMilli(), Sources: []string{cmp.Or(opts.Source, ".")}}
+	packages := map[string]*coberturaPackage{}
+	pkgLines := map[string][2]int{} // The covered and valid lines of each package.
+
+	for _, file := range p.lineHits() {
+		pkg := path.Dir(file.name)
+		if packages[pkg] == nil {
+			packages[pkg] = &coberturaPackage{Name: pkg}
+		}
+
+		class := coberturaClass{Name: path.Base(file.name), Filename: strings.TrimPrefix(file.name, opts.Module+"/")}
+		covered := 0
+
+		for _, line := range slices.Sorted(maps.Keys(file.hits)) {
+			class.Lines = append(class.Lines, coberturaLine{Number: line, Hits: file.hits[line]})
+
+			if file.hits[line] > 0 {
+				covered++
+			}
+		}
+
+		class.LineRate = lineRate(covered, len(class.Lines))
+		packages[pkg].Classes = append(packages[pkg].Classes, class)
+		pkgLines[pkg] = [2]int{pkgLines[pkg][0] + covered, pkgLines[pkg][1] + len(class.Lines)}
+		report.LinesCovered += covered
+		report.LinesValid += len(class.Lines)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(packages)) {
+		pkg := packages[name]
+		pkg.LineRate = lineRate(pkgLines[name][0], pkgLines[name][1])
+		report.Packages = append(report.Packages, *pkg)
+	}
+
+	report.LineRate = lineRate(report.LinesCovered, report.LinesValid)
+
+	if _, err := io.WriteString(w, xml.Header+
+		`<!DOCTYPE coverage SYSTEM "http://cobertura.sourceforge.net/xml/coverage-04.dtd">`+"\n"); err != nil {
+		return err //nolint:wrapcheck // ok
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("failed to encode the Cobertura report: %w", err)
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err //nolint:wrapcheck // ok
+}
+
+func lineRate(covered, total int) float64 {
+	return percent(covered, total) / 100 //nolint:mnd // ok
+}
@@ -0,0 +1,64 @@
+package badge
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteCobertura(t *testing.T) {
+	t.Parallel()
+
+	p, err := ParseProfile(strings.NewReader("mode: set\n" +
+		"m/a/a.go:3.10,5.2 2 1\nm/a/a.go:5.2,6.3 1 0\nm/a/a.go:8.1,8.20 1 0\nm/b.go:1.1,2.2 1 3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf strings.Builder
+
+	opts := CoberturaOptions{Module: "m", Source: "/src/m", Timestamp: time.UnixMilli(1700000000000)}
+	if err = p.WriteCobertura(&buf, opts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE coverage SYSTEM "http://cobertura.sourceforge.net/xml/coverage-04.dtd">
+<coverage line-rate="0.7142857142857143" branch-rate="0" lines-covered="5" lines-valid="7" branches-covered="0" branches-valid="0" complexity="0" version="" timestamp="1700000000000">
+  <sources>
+    <source>/src/m</source>
+  </sources>
+  <packages>
+    <package name="m" line-rate="1" branch-rate="0" complexity="0">
+      <classes>
+        <class name="b.go" filename="b.go" line-rate="1" branch-rate="0" complexity="0">
+          <methods></methods>
+          <lines>
+            <line number="1" hits="3"></line>
+            <line number="2" hits="3"></line>
+          </lines>
+        </class>
+      </classes>
+    </package>
+    <package name="m/a" line-rate="0.6" branch-rate="0" complexity="0">
+      <classes>
+        <class name="a.go" filename="a/a.go" line-rate="0.6" branch-rate="0" complexity="0">
+          <methods></methods>
+          <lines>
+            <line number="3" hits="1"></line>
+            <line number="4" hits="1"></line>
+            <line number="5" hits="1"></line>
+            <line number="6" hits="0"></line>
+            <line number="8" hits="0"></line>
+          </lines>
+        </class>
+      </classes>
+    </package>
+  </packages>
+</coverage>
+`
+
+	if got := buf.String(); got != want {
+		t.Errorf("WriteCobertura() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,299 @@
+package badge
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// shieldsColors are the named colors of shields.io, which take
+// precedence over the CSS ones (i.e. "green" is #97ca00, not #008000).
+var shieldsColors = map[string]string{ //nolint:gochecknoglobals // ok
+	"brightgreen":   "#44cc11",
+	"green":         "#97ca00",
+	"yellowgreen":   "#a4a61d",
+	"yellow":        "#dfb317",
+	"orange":        "#fe7d37",
+	"red":           "#e05d44",
+	"blue":          "#007ec6",
+	"grey":          "#555555",
+	"gray":          "#555555",
+	"lightgrey":     "#9f9f9f",
+	"lightgray":     "#9f9f9f",
+	"success":       "#44cc11",
+	"important":     "#fe7d37",
+	"critical":      "#e05d44",
+	"informational": "#007ec6",
+	"inactive":      "#9f9f9f",
+}
+
+// cssColors are the CSS named colors, https://www.w3.org/TR/css-color-4/#named-colors.
+var cssColors = map[string]string{ //nolint:gochecknoglobals // ok
+	"aliceblue": "#f0f8ff", "antiquewhite": "#faebd7", "aqua": "#00ffff", "aquamarine": "#7fffd4",
+	"azure": "#f0ffff", "beige": "#f5f5dc", "bisque": "#ffe4c4", "black": "#000000",
+	"blanchedalmond": "#ffebcd", "blue": "#0000ff", "blueviolet": "#8a2be2", "brown": "#a52a2a",
+	"burlywood": "#deb887", "cadetblue": "#5f9ea0", "chartreuse": "#7fff00", "chocolate": "#d2691e",
+	"coral": "#ff7f50", "cornflowerblue": "#6495ed", "cornsilk": "#fff8dc", "crimson": "#dc143c",
+	"cyan": "#00ffff", "darkblue": "#00008b", "darkcyan": "#008b8b", "darkgoldenrod": "#b8860b",
+	"darkgray": "#a9a9a9", "darkgreen": "#006400", "darkgrey": "#a9a9a9", "darkkhaki": "#bdb76b",
+	"darkmagenta": "#8b008b", "darkolivegreen": "#556b2f", "darkorange": "#ff8c00", "darkorchid": "#9932cc",
+	"darkred": "#8b0000", "darksalmon": "#e9967a", "darkseagreen": "#8fbc8f", "darkslateblue": "#483d8b",
+	"darkslategray": "#2f4f4f", "darkslategrey": "#2f4f4f", "darkturquoise": "#00ced1", "darkviolet": "#9400d3",
+	"deeppink": "#ff1493", "deepskyblue": "#00bfff", "dimgray": "#696969", "dimgrey": "#696969",
+	"dodgerblue": "#1e90ff", "firebrick": "#b22222", "floralwhite": "#fffaf0", "forestgreen": "#228b22",
+	"fuchsia": "#ff00ff", "gainsboro": "#dcdcdc", "ghostwhite": "#f8f8ff", "gold": "#ffd700",
+	"goldenrod": "#daa520", "gray": "#808080", "green": "#008000", "greenyellow": "#adff2f",
+	"grey": "#808080", "honeydew": "#f0fff0", "hotpink": "#ff69b4", "indianred": "#cd5c5c",
+	"indigo": "#4b0082", "ivory": "#fffff0", "khaki": "#f0e68c", "lavender": "#e6e6fa",
+	"lavenderblush": "#fff0f5", "lawngreen": "#7cfc00", "lemonchiffon": "#fffacd", "lightblue": "#add8e6",
+	"lightcoral": "#f08080", "lightcyan": "#e0ffff", "lightgoldenrodyellow": "#fafad2", "lightgray": "#d3d3d3",
+	"lightgreen": "#90ee90", "lightgrey": "#d3d3d3", "lightpink": "#ffb6c1", "lightsalmon": "#ffa07a",
+	"lightseagreen": "#20b2aa", "lightskyblue": "#87cefa", "lightslategray": "#778899", "lightslategrey": "#778899",
+	"lightsteelblue": "#b0c4de", "lightyellow": "#ffffe0", "lime": "#00ff00", "limegreen": "#32cd32",
+	"linen": "#faf0e6", "magenta": "#ff00ff", "maroon": "#800000", "mediumaquamarine": "#66cdaa",
+	"mediumblue": "#0000cd", "mediumorchid": "#ba55d3", "mediumpurple": "#9370db", "mediumseagreen": "#3cb371",
+	"mediumslateblue": "#7b68ee", "mediumspringgreen": "#00fa9a", "mediumturquoise": "#48d1cc",
+	"mediumvioletred": "#c71585", "midnightblue": "#191970", "mintcream": "#f5fffa", "mistyrose": "#ffe4e1",
+	"moccasin": "#ffe4b5", "navajowhite": "#ffdead", "navy": "#000080", "oldlace": "#fdf5e6",
+	"olive": "#808000", "olivedrab": "#6b8e23", "orange": "#ffa500", "orangered": "#ff4500",
+	"orchid": "#da70d6", "palegoldenrod": "#eee8aa", "palegreen": "#98fb98", "paleturquoise": "#afeeee",
+	"palevioletred": "#db7093", "papayawhip": "#ffefd5", "peachpuff": "#ffdab9", "peru": "#cd853f",
+	"pink": "#ffc0cb", "plum": "#dda0dd", "powderblue": "#b0e0e6", "purple": "#800080",
+	"rebeccapurple": "#663399", "red": "#ff0000", "rosybrown": "#bc8f8f", "royalblue": "#4169e1",
+	"saddlebrown": "#8b4513", "salmon": "#fa8072", "sandybrown": "#f4a460", "seagreen": "#2e8b57",
+	"seashell": "#fff5ee", "sienna": "#a0522d", "silver": "#c0c0c0", "skyblue": "#87ceeb",
+	"slateblue": "#6a5acd", "slategray": "#708090", "slategrey": "#708090", "snow": "#fffafa",
+	"springgreen": "#00ff7f", "steelblue": "#4682b4", "tan": "#d2b48c", "teal": "#008080",
+	"thistle": "#d8bfd8", "tomato": "#ff6347", "turquoise": "#40e0d0", "violet": "#ee82ee",
+	"wheat": "#f5deb3", "white": "#ffffff", "whitesmoke": "#f5f5f5", "yellow": "#ffff00",
+	"yellowgreen": "#9acd32",
+}
+
+// ParseColor parses a color given as hex (#rgb, #rrggbb or #rrggbbaa), as a
+// shields.io or CSS color name, or as a CSS rgb(), rgba(), hsl() or hsla()
+// function, returning its canonical hex form. Hex colors are returned as is,
+// the others as #rrggbb, or #rrggbbaa if not fully opaque.
+func ParseColor(color string) (string, error) {
+	color = strings.TrimSpace(color)
+
+	if strings.HasPrefix(color, "#") {
+		if !isValidHexColor(color) {
+			return "", fmt.Errorf("%w: %s", ErrInvalidColor, color)
+		}
+
+		return color, nil
+	}
+
+	name := strings.ToLower(color)
+	if hex, ok := shieldsColors[name]; ok {
+		return hex, nil
+	}
+
+	if hex, ok := cssColors[name]; ok {
+		return hex, nil
+	}
+
+	fn, args, ok := strings.Cut(strings.TrimSuffix(name, ")"), "(")
+	if !ok || !strings.HasSuffix(name, ")") {
+		return "", fmt.Errorf("%w: %s", ErrInvalidColor, color)
+	}
+
+	var (
+		hex string
+		err error
+	)
+
+	switch fn {
+	case "rgb", "rgba":
+		hex, err = parseRGBFunc(args)
+	case "hsl", "hsla":
+		hex, err = parseHSLFunc(args)
+	default:
+		err = fmt.Errorf("unknown function %s", fn)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %w", ErrInvalidColor, color, err)
+	}
+
+	return hex, nil
+}
+
+// colorArgs splits the arguments of a CSS color function, which may use
+// either the legacy "r, g, b, a" or the modern "r g b / a" syntax.
+func colorArgs(args string) (channels []string, alpha string, err error) {
+	args = strings.ReplaceAll(args, ",", " ")
+
+	main, alpha, hasSlash := strings.Cut(args, "/")
+	channels = strings.Fields(main)
+
+	switch {
+	case len(channels) == 4 && !hasSlash: //nolint:mnd // ok
+		channels, alpha = channels[:3], channels[3]
+	case len(channels) != 3: //nolint:mnd // ok
+		return nil, "", fmt.Errorf("expected 3 channels, got %d", len(channels))
+	}
+
+	return channels, strings.TrimSpace(alpha), nil
+}
+
+//nolint:mnd // ok
+func parseRGBFunc(args string) (string, error) {
+	channels, alpha, err := colorArgs(args)
+	if err != nil {
+		return "", err
+	}
+
+	rgb := [3]int64{}
+
+	for i, ch := range channels {
+		v, err := parseNumberOrPercent(ch, 255)
+		if err != nil {
+			return "", err
+		}
+
+		rgb[i] = int64(math.Round(math.Max(0, math.Min(255, v))))
+	}
+
+	return withAlpha(rgbToHex(rgb[0], rgb[1], rgb[2]), alpha)
+}
+
+//nolint:mnd // ok
+func parseHSLFunc(args string) (string, error) {
+	channels, alpha, err := colorArgs(args)
+	if err != nil {
+		return "", err
+	}
+
+	h, err := parseFinite(strings.TrimSuffix(channels[0], "deg"))
+	if err != nil {
+		return "", fmt.Errorf("invalid hue %q", channels[0])
+	}
+
+	s, err := parseNumberOrPercent(channels[1], 100)
+	if err != nil {
+		return "", err
+	}
+
+	l, err := parseNumberOrPercent(channels[2], 100)
+	if err != nil {
+		return "", err
+	}
+
+	r, g, b := hslToRGB(h, math.Max(0, math.Min(1, s/100)), math.Max(0, math.Min(1, l/100)))
+
+	return withAlpha(rgbToHex(r, g, b), alpha)
+}
+
+// parseNumberOrPercent parses a number, or a percentage of full.
+//
+//nolint:mnd // ok
+func parseNumberOrPercent(s string, full float64) (float64, error) {
+	pc, isPercent := strings.CutSuffix(s, "%")
+
+	v, err := parseFinite(pc)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+
+	if isPercent {
+		v = v * full / 100
+	}
+
+	return v, nil
+}
+
+// parseFinite parses a number, rejecting NaN and the infinities.
+func parseFinite(s string) (float64, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err == nil && (math.IsNaN(v) || math.IsInf(v, 0)) {
+		err = strconv.ErrSyntax
+	}
+
+	return v, err //nolint:wrapcheck // ok
+}
+
+// withAlpha appends the alpha channel to hex, unless it is fully opaque.
+//
+//nolint:mnd // ok
+func withAlpha(hex, alpha string) (string, error) {
+	if alpha == "" {
+		return hex, nil
+	}
+
+	a, err := parseNumberOrPercent(alpha, 1)
+	if err != nil {
+		return "", err
+	}
+
+	if a = math.Max(0, math.Min(1, a)); a == 1 {
+		return hex, nil
+	}
+
+	return fmt.Sprintf("%s%02x", hex, int64(math.Round(a*255))), nil
+}
+
+// hslToRGB converts HSL (hue in degrees, saturation and lightness in [0, 1]) to RGB.
+//
+//nolint:mnd // ok
+func hslToRGB(h, s, l float64) (r, g, b int64) {
+	h = math.Mod(math.Mod(h, 360)+360, 360)
+	c := (1 - math.Abs(2*l-1)) * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := l - c/2
+
+	var r1, g1, b1 float64
+
+	switch {
+	case h < 60:
+		r1, g1 = c, x
+	case h < 120:
+		r1, g1 = x, c
+	case h < 180:
+		g1, b1 = c, x
+	case h < 240:
+		g1, b1 = x, c
+	case h < 300:
+		r1, b1 = x, c
+	default:
+		r1, b1 = c, x
+	}
+
+	f := func(v float64) int64 { return int64(math.Round((v + m) * 255)) }
+
+	return f(r1), f(g1), f(b1)
+}
+
+// emojiColors are the chromatic colored circle emojis, with their Twemoji colors.
+var emojiColors = []struct{ emoji, color string }{ //nolint:gochecknoglobals // ok
+	{"🔴", "#dd2e44"}, {"🟠", "#f4900c"}, {"🟡", "#fdcb58"}, {"🟢", "#78b159"}, {"🔵", "#55acee"}, {"🟣", "#aa8ed6"},
+}
+
+// ColorEmoji returns the colored circle emoji closest in hue to the given (hex)
+// color, or the black or white one for grays, i.e. for showing the level colors
+// in Markdown.
+//
+//nolint:mnd // ok
+func ColorEmoji(color string) string {
+	c := hexToOklab(color)
+
+	if c.chroma() < 0.04 {
+		if c.L < 0.5 {
+			return "⚫"
+		}
+
+		return "⚪"
+	}
+
+	closest, dist := "", math.Inf(1)
+
+	for _, e := range emojiColors {
+		d := math.Abs(c.hue() - hexToOklab(e.color).hue())
+		if d = min(d, 2*math.Pi-d); d < dist {
+			closest, dist = e.emoji, d
+		}
+	}
+
+	return closest
+}
@@ -0,0 +1,95 @@
+package badge
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		color       string
+		expected    string
+		shouldError bool
+	}{
+		{name: "3-digit hex kept as is", color: "#0F0", expected: "#0F0"},
+		{name: "6-digit hex kept as is", color: "#44cc11", expected: "#44cc11"},
+		{name: "8-digit hex kept as is", color: "#44cc1180", expected: "#44cc1180"},
+		{name: "Surrounding spaces", color: "  #44cc11 ", expected: "#44cc11"},
+		{name: "Shields name", color: "brightgreen", expected: "#44cc11"},
+		{name: "Shields name wins over CSS", color: "green", expected: "#97ca00"},
+		{name: "Shields alias", color: "critical", expected: "#e05d44"},
+		{name: "CSS name", color: "rebeccapurple", expected: "#663399"},
+		{name: "Name is case insensitive", color: "DarkOrange", expected: "#ff8c00"},
+		{name: "rgb legacy syntax", color: "rgb(68, 204, 17)", expected: "#44cc11"},
+		{name: "rgb modern syntax", color: "rgb(68 204 17)", expected: "#44cc11"},
+		{name: "rgb percentages", color: "rgb(100%, 0%, 50%)", expected: "#ff0080"},
+		{name: "rgb clamps channels", color: "rgb(300, -5, 17)", expected: "#ff0011"},
+		{name: "rgba opaque", color: "rgba(68, 204, 17, 1)", expected: "#44cc11"},
+		{name: "rgba translucent", color: "rgba(68, 204, 17, 0.5)", expected: "#44cc1180"},
+		{name: "rgb modern alpha", color: "RGB(68 204 17 / 25%)", expected: "#44cc1140"},
+		{name: "hsl", color: "hsl(120, 100%, 50%)", expected: "#00ff00"},
+		{name: "hsl with deg", color: "hsl(240deg 100% 25%)", expected: "#000080"},
+		{name: "hsl negative hue", color: "hsl(-120, 100%, 50%)", expected: "#0000ff"},
+		{name: "hsl gray", color: "hsl(0, 0%, 50%)", expected: "#808080"},
+		{name: "hsla", color: "hsla(0, 100%, 50%, 0)", expected: "#ff000000"},
+		{name: "hsl all sectors", color: "hsl(300, 100%, 50%)", expected: "#ff00ff"},
+		{name: "Invalid hex", color: "#00ff", shouldError: true},
+		{name: "Unknown name", color: "blurple", shouldError: true},
+		{name: "Unknown function", color: "lab(50 20 30)", shouldError: true},
+		{name: "Unclosed function", color: "rgb(1, 2, 3", shouldError: true},
+		{name: "Too few channels", color: "rgb(1, 2)", shouldError: true},
+		{name: "Too many channels", color: "rgb(1 2 3 4 / 5)", shouldError: true},
+		{name: "Invalid channel", color: "rgb(1, x, 3)", shouldError: true},
+		{name: "Invalid alpha", color: "rgba(1, 2, 3, x)", shouldError: true},
+		{name: "Invalid hue", color: "hsl(x, 1%, 1%)", shouldError: true},
+		{name: "NaN channel", color: "rgb(nan, 0, 0)", shouldError: true},
+		{name: "Infinite channel", color: "rgb(0, -Inf, 0)", shouldError: true},
+		{name: "Infinite hue", color: "hsl(inf, 1, 1)", shouldError: true},
+		{name: "NaN percentage", color: "hsl(0, NaN%, 50%)", shouldError: true},
+		{name: "NaN alpha", color: "rgba(1, 2, 3, nan)", shouldError: true},
+		{name: "Invalid saturation", color: "hsl(1, x, 1%)", shouldError: true},
+		{name: "Invalid lightness", color: "hsl(1, 1%, x)", shouldError: true},
+		{name: "Empty", color: "", shouldError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseColor(tt.color)
+			if tt.shouldError {
+				if !errors.Is(err, ErrInvalidColor) {
+					t.Errorf("ParseColor(%q) = %q, %v, want ErrInvalidColor", tt.color, got, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if got != tt.expected {
+				t.Errorf("ParseColor(%q) = %q, want %q", tt.color, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestColorEmoji(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"#44cc11": "🟢", "#97ca00": "🟢", "#dfb317": "🟡", "#ff8c00": "🟠", "#fe7d37": "🟠",
+		"#ff0001": "🔴", "#e05d44": "🔴", "#007ec6": "🔵", "#9f9f9f": "⚪", "#000": "⚫",
+	}
+
+	for color, expected := range tests {
+		if got := ColorEmoji(color); got != expected {
+			t.Errorf("ColorEmoji(%q) = %s, want %s", color, got, expected)
+		}
+	}
+}
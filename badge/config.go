@@ -0,0 +1,408 @@
+package badge
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Config file formats.
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatTOML = "toml"
+)
+
+// Errors returned when loading a Config.
+var (
+	// ErrUnknownFormat is returned for config formats other than
+	// FormatJSON, FormatYAML and FormatTOML.
+	ErrUnknownFormat  = errors.New("unknown config format")
+	ErrUnknownProfile = errors.New("unknown profile")
+	ErrNestedProfiles = errors.New("profiles cannot define profiles")
+)
+
+// Config is the stampli configuration, as read from stampli.json (or from
+// its YAML or TOML equivalents, using the same keys).
+type Config struct {
+	Schema          string   `json:"$schema,omitempty"` // Editors' JSON Schema reference, see Schema.
+	Levels          Levels   `json:"levels,omitzero"`
+	DarkLevels      Levels   `json:"darkLevels,omitzero"`
+	CoveragePC      *float64 `json:"-"`
+	TestCommand     string   `json:"testCommand"`
+	BadgeType       string   `json:"badgeType,omitempty"`
+	ColorMode       string   `json:"colorMode,omitempty"`
+	CoverageFile    string   `json:"coverageFile,omitempty"`
+	OutputFile      string   `json:"outputFile"`
+	MinCoverage     float64  `json:"minCoverage,omitempty"`
+	FileMinCoverage float64  `json:"fileMinCoverage,omitempty"` // Of each file, MinCoverage if 0.
+	MarkdownFile    string   `json:"markdownFile,omitempty"`    // The Markdown report, not written if empty.
+	BaselineFile    string   `json:"baselineFile,omitempty"`    // The coverage profile the report compares to.
+	GitLab          bool     `json:"gitlab,omitempty"`          // Print the coverage line for the GitLab regex.
+	CoberturaFile   string   `json:"coberturaFile,omitempty"`   // The Cobertura XML report, not written if empty.
+	HTMLDir         string   `json:"htmlDir,omitempty"`         // The HTML report directory, not written if empty.
+	MetricsFile     string   `json:"metricsFile,omitempty"`     // The OpenMetrics file, not written if empty.
+	ConfigFile      string   `json:"-"`
+	Template        string   `json:"template"`
+	DumpTemplate    bool     `json:"dumpTemplate"`
+	DumpConfig      bool     `json:"dumpConfig"`
+	Quiet           bool     `json:"quiet"`
+	AutoClean       bool     `json:"autoClean"`
+	// Profiles are named sets of overrides of the other keys, see ApplyProfile.
+	Profiles map[string]*ConfigProfile `json:"profiles,omitempty"`
+}
+
+// ConfigProfile is a named set of config overrides: only the Keys it sets
+// override the top-level config.
+type ConfigProfile struct {
+	Config
+
+	Keys []string // The (JSON) keys the profile sets, sorted.
+}
+
+//go:embed stampli.json
+var defaultConfig string
+
+// DefaultConfig returns the built-in configuration, as JSON.
+func DefaultConfig() string {
+	return defaultConfig
+}
+
+// LoadConfig returns the built-in configuration overridden
+// by the given config file, if not empty.
+func LoadConfig(filename string) (cfg Config, err error) {
+	if err = json.Unmarshal([]byte(defaultConfig), &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to load embedded defaults: %w", err)
+	}
+
+	if filename != "" {
+		err = cfg.LoadFile(filename)
+	}
+
+	return
+}
+
+// DefaultConfigAs returns the built-in configuration in the given format.
+func DefaultConfigAs(format string) (string, error) {
+	return DefaultConfigWith(format, nil)
+}
+
+// DefaultConfigWith returns the built-in configuration, with the given
+// keys overridden, in the given format.
+func DefaultConfigWith(format string, overrides map[string]any) (string, error) {
+	if format == FormatJSON && len(overrides) == 0 {
+		return defaultConfig, nil
+	}
+
+	var m map[string]any
+	if err := json.Unmarshal([]byte(defaultConfig), &m); err != nil {
+		return "", fmt.Errorf("failed to load embedded defaults: %w", err)
+	}
+
+	maps.Copy(m, overrides)
+
+	var buf bytes.Buffer
+
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+
+		if err := enc.Encode(m); err != nil {
+			return "", fmt.Errorf("failed to encode defaults: %w", err)
+		}
+	case FormatYAML:
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2) //nolint:mnd // ok
+
+		if err := enc.Encode(m); err != nil {
+			return "", fmt.Errorf("failed to encode defaults: %w", err)
+		}
+	case FormatTOML:
+		if err := toml.NewEncoder(&buf).Encode(m); err != nil {
+			return "", fmt.Errorf("failed to encode defaults: %w", err)
+		}
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+
+	return buf.String(), nil
+}
+
+// FormatOf returns the config format of filename, based on its extension:
+// FormatYAML for .yaml and .yml, FormatTOML for .toml, FormatJSON otherwise.
+func FormatOf(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	default:
+		return FormatJSON
+	}
+}
+
+// LoadFile overrides c with the values set in the given config file,
+// in the format given by FormatOf. Relative paths are resolved against the
+// directory of the config file. Unknown keys and values of the wrong type
+// are reported as a *ConfigError.
+func (c *Config) LoadFile(filename string) error {
+	raw, data, keys, err := readConfigFile(filename)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	if err = dec.Decode(c); err != nil {
+		return newConfigError(filename, raw, err)
+	}
+
+	dir := filepath.Dir(filename)
+	c.resolvePaths(dir, keys)
+
+	if !slices.Contains(keys, "profiles") {
+		return nil
+	}
+
+	// Only resolve the profiles of this file, not the ones of previous ones.
+	var file struct {
+		Profiles map[string]json.RawMessage `json:"profiles"`
+	}
+
+	if err = json.Unmarshal(data, &file); err != nil {
+		return newConfigError(filename, raw, err)
+	}
+
+	for name := range file.Profiles {
+		c.Profiles[name].resolvePaths(dir, c.Profiles[name].Keys)
+	}
+
+	return nil
+}
+
+// resolvePaths resolves the relative outputFile, markdownFile, baselineFile,
+// coberturaFile, htmlDir, metricsFile and template paths against dir, for the
+// given keys only.
+func (c *Config) resolvePaths(dir string, keys []string) {
+	for _, key := range keys {
+		switch key {
+		case "outputFile":
+			c.OutputFile = resolvePath(dir, c.OutputFile)
+		case "template":
+			c.Template = resolvePath(dir, c.Template)
+		case "markdownFile":
+			c.MarkdownFile = resolvePath(dir, c.MarkdownFile)
+		case "baselineFile":
+			c.BaselineFile = resolvePath(dir, c.BaselineFile)
+		case "coberturaFile":
+			c.CoberturaFile = resolvePath(dir, c.CoberturaFile)
+		case "htmlDir":
+			c.HTMLDir = resolvePath(dir, c.HTMLDir)
+		case "metricsFile":
+			c.MetricsFile = resolvePath(dir, c.MetricsFile)
+		}
+	}
+}
+
+// ApplyProfile overrides c with the values set by the named profile
+// and returns the keys it set.
+func (c *Config) ApplyProfile(name string) ([]string, error) {
+	p, ok := c.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (available: %s)", ErrUnknownProfile, name, strings.Join(c.ProfileNames(), ", "))
+	}
+
+	return p.Keys, c.MergeKeys(&p.Config, p.Keys...)
+}
+
+// ProfileNames returns the names of the profiles, sorted.
+func (c *Config) ProfileNames() []string {
+	return slices.Sorted(maps.Keys(c.Profiles))
+}
+
+// UnmarshalJSON decodes the profile strictly, recording the keys it sets.
+//
+//nolint:wrapcheck // ok
+func (p *ConfigProfile) UnmarshalJSON(data []byte) error {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return err
+	}
+
+	if _, ok := keys["profiles"]; ok {
+		return ErrNestedProfiles
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&p.Config); err != nil {
+		return err
+	}
+
+	p.Keys = slices.Sorted(maps.Keys(keys))
+
+	return nil
+}
+
+// MarshalJSON encodes the keys set by the profile only.
+//
+//nolint:wrapcheck // ok
+func (p ConfigProfile) MarshalJSON() ([]byte, error) {
+	values, err := p.Values(p.Keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(values)
+}
+
+// ConfigFileKeys returns the (JSON) keys set in the given config file.
+func ConfigFileKeys(filename string) ([]string, error) {
+	_, _, keys, err := readConfigFile(filename)
+	return keys, err
+}
+
+// readConfigFile returns the raw content of the given config file,
+// the same converted to JSON, and the keys set in it.
+func readConfigFile(filename string) (raw, data []byte, keys []string, err error) {
+	raw, err = os.ReadFile(filename) //nolint:gosec // ok
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load config file %s: %w", filename, err)
+	}
+
+	var m map[string]json.RawMessage
+
+	if data, err = configToJSON(raw, FormatOf(filename)); err == nil {
+		err = json.Unmarshal(data, &m)
+	}
+
+	if err != nil {
+		return nil, nil, nil, newConfigError(filename, raw, err)
+	}
+
+	return raw, data, slices.Sorted(maps.Keys(m)), nil
+}
+
+func resolvePath(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(dir, path)
+}
+
+// configToJSON converts the data of a YAML or TOML config to JSON, so that all
+// formats share the JSON decoding (and the Levels forms it accepts).
+func configToJSON(data []byte, format string) ([]byte, error) {
+	var (
+		m   map[string]any
+		err error
+	)
+
+	switch format {
+	case FormatYAML:
+		err = yaml.Unmarshal(data, &m)
+	case FormatTOML:
+		err = toml.Unmarshal(data, &m)
+	default:
+		return data, nil
+	}
+
+	if err != nil {
+		return nil, err //nolint:wrapcheck // ok
+	}
+
+	return json.Marshal(m) //nolint:wrapcheck // ok
+}
+
+// Merge overrides c with the values of other.
+func (c *Config) Merge(other *Config) (err error) {
+	js, err := json.Marshal(other)
+	if err != nil {
+		return fmt.Errorf("failed to marshal other config: %w", err)
+	}
+
+	if err = json.Unmarshal(js, c); err != nil {
+		return fmt.Errorf("failed to unmarshal into current config: %w", err)
+	}
+
+	if other.CoveragePC != nil {
+		c.CoveragePC = other.CoveragePC
+	}
+
+	return
+}
+
+// MergeKeys overrides c with the values of other for the given
+// (JSON) keys only, i.e. the ones explicitly set by the user.
+func (c *Config) MergeKeys(other *Config, keys ...string) error {
+	picked, err := other.Values(keys...)
+	if err != nil {
+		return err
+	}
+
+	js, err := json.Marshal(picked)
+	if err != nil {
+		return fmt.Errorf("failed to marshal other config: %w", err)
+	}
+
+	if err = json.Unmarshal(js, c); err != nil {
+		return fmt.Errorf("failed to unmarshal into current config: %w", err)
+	}
+
+	return nil
+}
+
+// Values returns the JSON values of the given keys of c, including
+// the zero ones (which the omitempty keys would otherwise drop).
+func (c *Config) Values(keys ...string) (map[string]json.RawMessage, error) {
+	picked := map[string]json.RawMessage{}
+	v := reflect.ValueOf(c).Elem()
+
+	for i := range v.NumField() {
+		key, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("json"), ",")
+		if key == "" || key == "-" || !slices.Contains(keys, key) {
+			continue
+		}
+
+		js, err := json.Marshal(v.Field(i).Interface())
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal config key %s: %w", key, err)
+		}
+
+		picked[key] = js
+	}
+
+	return picked, nil
+}
+
+// LoadTemplate replaces the Template file path with its content,
+// or with the built-in template of BadgeType if no path is set.
+func (c *Config) LoadTemplate() error {
+	if c.Template != "" {
+		data, err := os.ReadFile(c.Template)
+		if err != nil {
+			return fmt.Errorf("could not read template file %s: %w", c.Template, err)
+		}
+
+		c.Template = string(data)
+	} else {
+		c.Template = DefaultTemplate(c.BadgeType)
+	}
+
+	return nil
+}
@@ -0,0 +1,632 @@
+package badge
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestConfigMerge(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		base      *Config
+		other     *Config
+		expected  *Config
+		wantError bool
+	}{
+		{
+			name:     "Empty configs",
+			base:     &Config{},
+			other:    &Config{},
+			expected: &Config{},
+		},
+		{
+			name: "Merge non-empty into empty",
+			base: &Config{},
+			other: &Config{
+				TestCommand: "go test",
+				OutputFile:  "badge.svg",
+				Quiet:       true,
+			},
+			expected: &Config{
+				TestCommand: "go test",
+				OutputFile:  "badge.svg",
+				Quiet:       true,
+			},
+		},
+		{
+			name: "Merge into existing config",
+			base: &Config{
+				TestCommand:  "original",
+				OutputFile:   "original.svg",
+				Quiet:        false,
+				DumpTemplate: false,
+			},
+			other: &Config{
+				TestCommand: "go test",
+				Quiet:       true,
+			},
+			expected: &Config{
+				TestCommand:  "go test",
+				OutputFile:   "", // JSON marshal/unmarshal overwrites with zero value
+				Quiet:        true,
+				DumpTemplate: false, // JSON marshal/unmarshal overwrites with zero value
+			},
+		},
+		{
+			name: "Merge levels",
+			base: &Config{
+				Levels: Levels{{Threshold: 90, Color: "#00ff00"}},
+			},
+			other: &Config{
+				Levels: Levels{{Threshold: 70, Color: "#ffff00"}, {Threshold: 0, Color: "#ff0000"}},
+			},
+			expected: &Config{
+				Levels: Levels{{Threshold: 70, Color: "#ffff00"}, {Threshold: 0, Color: "#ff0000"}},
+			},
+		},
+		{
+			name: "JSON roundtrip test",
+			base: &Config{
+				TestCommand:  "go test",
+				OutputFile:   "badge.svg",
+				Quiet:        true,
+				AutoClean:    false,
+				DumpTemplate: true,
+				Levels:       Levels{{Threshold: 90, Color: "#00ff00"}, {Threshold: 0, Color: "#ff0000"}},
+			},
+			other: &Config{
+				TestCommand: "new command",
+				Quiet:       false,
+				AutoClean:   true,
+			},
+			expected: &Config{
+				TestCommand:  "new command",
+				OutputFile:   "", // JSON marshal/unmarshal overwrites with zero value
+				Quiet:        false,
+				AutoClean:    true,
+				DumpTemplate: false,    // JSON marshal/unmarshal overwrites with zero value
+				Levels:       Levels{}, // JSON marshal/unmarshal overwrites with zero value
+			},
+		},
+		{
+			name: "Merge with CoveragePC set",
+			base: &Config{
+				TestCommand: "original",
+				OutputFile:  "original.svg",
+			},
+			other: &Config{
+				TestCommand: "new command",
+				CoveragePC:  func() *float64 { v := 85.5; return &v }(),
+			},
+			expected: &Config{
+				TestCommand: "new command",
+				OutputFile:  "", // JSON marshal/unmarshal overwrites with zero value
+				CoveragePC:  func() *float64 { v := 85.5; return &v }(),
+			},
+		},
+		{
+			name: "Merge with nil CoveragePC",
+			base: &Config{
+				TestCommand: "original",
+				CoveragePC:  func() *float64 { v := 90.0; return &v }(),
+			},
+			other: &Config{
+				TestCommand: "new command",
+				CoveragePC:  nil,
+			},
+			expected: &Config{
+				TestCommand: "new command",
+				OutputFile:  "",                                         // JSON marshal/unmarshal overwrites with zero value
+				CoveragePC:  func() *float64 { v := 90.0; return &v }(), // Should keep base value since other is nil
+			},
+		},
+		{
+			name: "Merge overwriting CoveragePC",
+			base: &Config{
+				TestCommand: "original",
+				CoveragePC:  func() *float64 { v := 90.0; return &v }(),
+			},
+			other: &Config{
+				TestCommand: "new command",
+				CoveragePC:  func() *float64 { v := 75.2; return &v }(),
+			},
+			expected: &Config{
+				TestCommand: "new command",
+				OutputFile:  "", // JSON marshal/unmarshal overwrites with zero value
+				CoveragePC:  func() *float64 { v := 75.2; return &v }(),
+			},
+		},
+		{
+			name: "Merge with invalid levels causing unmarshal error",
+			base: &Config{
+				TestCommand: "original",
+			},
+			other: &Config{
+				TestCommand: "new command",
+				Levels:      Levels{}, // This will be modified to cause unmarshal error
+			},
+			wantError: false, // Even invalid levels won't cause JSON marshal/unmarshal to fail
+			expected: &Config{
+				TestCommand: "new command",
+				OutputFile:  "",
+				Levels:      Levels{},
+			},
+		},
+		{
+			name: "Merge with complex levels",
+			base: &Config{
+				TestCommand: "original",
+				Levels:      Levels{{Threshold: 50, Color: "#yellow"}},
+			},
+			other: &Config{
+				TestCommand: "new command",
+				Levels:      Levels{{Threshold: 90, Color: "#00ff00"}, {Threshold: 70, Color: "#ffff00"}, {Threshold: 0, Color: "#ff0000"}},
+			},
+			expected: &Config{
+				TestCommand: "new command",
+				OutputFile:  "",
+				Levels:      Levels{{Threshold: 90, Color: "#00ff00"}, {Threshold: 70, Color: "#ffff00"}, {Threshold: 0, Color: "#ff0000"}},
+			},
+		},
+		{
+			name: "Merge preserving base CoveragePC when other is nil",
+			base: &Config{
+				TestCommand: "original",
+				CoveragePC:  func() *float64 { v := 88.8; return &v }(),
+				Quiet:       true,
+			},
+			other: &Config{
+				TestCommand: "updated command",
+				Quiet:       false,
+				CoveragePC:  nil, // Explicitly nil
+			},
+			expected: &Config{
+				TestCommand: "updated command",
+				OutputFile:  "",
+				Quiet:       false,
+				CoveragePC:  func() *float64 { v := 88.8; return &v }(), // Should preserve base value
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.base.Merge(tt.other)
+			if tt.wantError && err == nil {
+				t.Error("Expected error but got none")
+				return
+			}
+
+			if !tt.wantError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+
+			if tt.expected != nil { //nolint:nestif // ok
+				if tt.base.TestCommand != tt.expected.TestCommand {
+					t.Errorf("TestCommand = %q, want %q", tt.base.TestCommand, tt.expected.TestCommand)
+				}
+
+				if tt.base.OutputFile != tt.expected.OutputFile {
+					t.Errorf("OutputFile = %q, want %q", tt.base.OutputFile, tt.expected.OutputFile)
+				}
+
+				if tt.base.Quiet != tt.expected.Quiet {
+					t.Errorf("Quiet = %v, want %v", tt.base.Quiet, tt.expected.Quiet)
+				}
+
+				if tt.base.AutoClean != tt.expected.AutoClean {
+					t.Errorf("AutoClean = %v, want %v", tt.base.AutoClean, tt.expected.AutoClean)
+				}
+
+				if tt.base.DumpTemplate != tt.expected.DumpTemplate {
+					t.Errorf("DumpTemplate = %v, want %v", tt.base.DumpTemplate, tt.expected.DumpTemplate)
+				}
+
+				if len(tt.expected.Levels) > 0 && !tt.base.Levels.eq(tt.expected.Levels) {
+					t.Errorf("Levels = %v, want %v", tt.base.Levels, tt.expected.Levels)
+				}
+
+				if tt.expected.CoveragePC != nil {
+					if tt.base.CoveragePC == nil {
+						t.Error("Expected CoveragePC to be set but it was nil")
+					} else if *tt.base.CoveragePC != *tt.expected.CoveragePC {
+						t.Errorf("CoveragePC = %v, want %v", *tt.base.CoveragePC, *tt.expected.CoveragePC)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"valid.json":   `{"outputFile": "custom.svg"}`,
+		"invalid.json": `{invalid json}`,
+		"valid.yaml":   "outputFile: custom.svg\nlevels: 90=#00ff00,=#ff0000\n",
+		"expanded.yml": "outputFile: custom.svg\nlevels:\n  - threshold: 90\n    color: green\n    textColor: '#000'\n  - color: red\n",
+		"invalid.yaml": "outputFile: [custom.svg\n",
+		"valid.toml":   "outputFile = \"custom.svg\"\nlevels = \"90=#00ff00,=#ff0000\"\n",
+		"expanded.toml": "outputFile = \"custom.svg\"\n\n[[levels]]\nthreshold = 90\ncolor = \"#00ff00\"\n\n" +
+			"[[levels]]\ncolor = \"#ff0000\"\n",
+		"invalid.toml":   "outputFile = custom.svg\n",
+		"no-default.yml": "levels:\n  - threshold: 90\n    color: green\n",
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to create config file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name           string
+		filename       string
+		expectError    bool
+		expectedOutput string
+		expectedLevels string
+	}{
+		{name: "Defaults only", expectedOutput: "coverage-badge.svg"},
+		{name: "Config file overrides defaults", filename: "valid.json", expectedOutput: "custom.svg"},
+		{name: "Invalid config file", filename: "invalid.json", expectError: true},
+		{name: "Missing config file", filename: "missing.json", expectError: true},
+		{name: "YAML config", filename: "valid.yaml", expectedOutput: "custom.svg", expectedLevels: "0=#ff0000,90=#00ff00"},
+		{name: "YAML expanded levels", filename: "expanded.yml", expectedOutput: "custom.svg", expectedLevels: "0=#e05d44,90=#97ca00/#000"},
+		{name: "Invalid YAML config", filename: "invalid.yaml", expectError: true},
+		{name: "TOML config", filename: "valid.toml", expectedOutput: "custom.svg", expectedLevels: "0=#ff0000,90=#00ff00"},
+		{name: "TOML expanded levels", filename: "expanded.toml", expectedOutput: "custom.svg", expectedLevels: "0=#ff0000,90=#00ff00"},
+		{name: "Invalid TOML config", filename: "invalid.toml", expectError: true},
+		{name: "Expanded levels without default", filename: "no-default.yml", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			filename := tt.filename
+			if filename != "" {
+				filename = filepath.Join(tempDir, filename)
+			}
+
+			cfg, err := LoadConfig(filename)
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			// Relative paths are resolved against the config file directory.
+			if filename != "" {
+				tt.expectedOutput = filepath.Join(tempDir, tt.expectedOutput)
+			}
+
+			if cfg.OutputFile != tt.expectedOutput {
+				t.Errorf("OutputFile = %q, want %q", cfg.OutputFile, tt.expectedOutput)
+			}
+
+			if cfg.TestCommand == "" || len(cfg.Levels) == 0 {
+				t.Errorf("Defaults not loaded: %+v", cfg)
+			}
+
+			if tt.expectedLevels != "" && cfg.Levels.String() != tt.expectedLevels {
+				t.Errorf("Levels = %q, want %q", cfg.Levels.String(), tt.expectedLevels)
+			}
+		})
+	}
+}
+
+func TestDefaultConfigAs(t *testing.T) {
+	t.Parallel()
+
+	defaults, err := LoadConfig("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, format := range []string{FormatJSON, FormatYAML, FormatTOML} {
+		t.Run(format, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := DefaultConfigAs(format)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			filename := filepath.Join(t.TempDir(), "stampli."+format)
+			if err = os.WriteFile(filename, []byte(data), 0o644); err != nil {
+				t.Fatalf("Failed to create config file: %v", err)
+			}
+
+			var cfg Config
+			if err = cfg.LoadFile(filename); err != nil {
+				t.Fatalf("Dumped config does not load back: %v", err)
+			}
+
+			if cfg.TestCommand != defaults.TestCommand || cfg.OutputFile != filepath.Join(filepath.Dir(filename), defaults.OutputFile) ||
+				!cfg.Levels.eq(defaults.Levels) || cfg.AutoClean != defaults.AutoClean {
+				t.Errorf("Config = %+v, want %+v", cfg, defaults)
+			}
+		})
+	}
+
+	if _, err = DefaultConfigAs("xml"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("DefaultConfigAs(xml) error = %v, want %v", err, ErrUnknownFormat)
+	}
+}
+
+func TestDefaultConfigWith(t *testing.T) {
+	t.Parallel()
+
+	for _, format := range []string{FormatJSON, FormatYAML, FormatTOML} {
+		t.Run(format, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := DefaultConfigWith(format, map[string]any{"template": "badge.tmpl"})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			filename := filepath.Join(t.TempDir(), "stampli."+format)
+			if err = os.WriteFile(filename, []byte(data), 0o644); err != nil {
+				t.Fatalf("Failed to create config file: %v", err)
+			}
+
+			cfg, err := LoadConfig(filename)
+			if err != nil {
+				t.Fatalf("Dumped config does not load back: %v", err)
+			}
+
+			if want := filepath.Join(filepath.Dir(filename), "badge.tmpl"); cfg.Template != want || !cfg.AutoClean {
+				t.Errorf("Template = %q, AutoClean = %v, want %q, true", cfg.Template, cfg.AutoClean, want)
+			}
+		})
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"stampli.json": FormatJSON,
+		"stampli.yaml": FormatYAML,
+		"stampli.YML":  FormatYAML,
+		"a/b.toml":     FormatTOML,
+		"stampli":      FormatJSON,
+	}
+
+	for filename, expected := range tests {
+		if got := FormatOf(filename); got != expected {
+			t.Errorf("FormatOf(%q) = %q, want %q", filename, got, expected)
+		}
+	}
+}
+
+func TestLoadTemplate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		setupFunc        func(t *testing.T, tempDir string) *Config
+		expectError      bool
+		expectedTemplate string
+	}{
+		{
+			name: "Load custom template file",
+			setupFunc: func(t *testing.T, tempDir string) *Config {
+				t.Helper()
+
+				testTemplateFile := filepath.Join(tempDir, "test-template.svg")
+				testTemplateContent := `<svg><text>{{.Coverage}}% test</text></svg>`
+
+				err := os.WriteFile(testTemplateFile, []byte(testTemplateContent), 0o644)
+				if err != nil {
+					t.Fatalf("Failed to create test template: %v", err)
+				}
+
+				return &Config{Template: testTemplateFile}
+			},
+			expectedTemplate: `<svg><text>{{.Coverage}}% test</text></svg>`,
+		},
+		{
+			name: "Use default template when no custom template",
+			setupFunc: func(t *testing.T, tempDir string) *Config {
+				t.Helper()
+
+				return &Config{Template: ""}
+			},
+			expectedTemplate: defaultTemplate,
+		},
+		{
+			name: "Error loading non-existent template file",
+			setupFunc: func(t *testing.T, tempDir string) *Config {
+				t.Helper()
+
+				return &Config{Template: filepath.Join(tempDir, "nonexistent-template.svg")}
+			},
+			expectError: true,
+		},
+		{
+			name: "Load template from directory without read permissions",
+			setupFunc: func(t *testing.T, tempDir string) *Config {
+				t.Helper()
+
+				if os.Geteuid() == 0 {
+					t.Skip("Skipping permission test when running as root")
+				}
+
+				restrictedDir := filepath.Join(tempDir, "restricted")
+				err := os.Mkdir(restrictedDir, 0o000)
+				if err != nil {
+					t.Fatalf("Failed to create restricted directory: %v", err)
+				}
+
+				return &Config{Template: filepath.Join(restrictedDir, "template.svg")}
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := t.TempDir()
+			c := tt.setupFunc(t, tempDir)
+
+			err := c.LoadTemplate()
+			if tt.expectError && err == nil {
+				t.Error("Expected error but got none")
+				return
+			}
+
+			if !tt.expectError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+
+			if !tt.expectError && tt.expectedTemplate != "" {
+				if c.Template != tt.expectedTemplate {
+					t.Errorf("Template content = %q, want %q", c.Template, tt.expectedTemplate)
+				}
+			}
+		})
+	}
+}
+
+func (l *Levels) eq(other Levels) bool {
+	return slices.Equal(*l, other)
+}
+
+func TestConfigMergeKeys(t *testing.T) {
+	t.Parallel()
+
+	base := Config{TestCommand: "original", OutputFile: "original.svg", Quiet: true, FileMinCoverage: 70, GitLab: true}
+	other := Config{TestCommand: "new command", MinCoverage: 80, Levels: Levels{{Threshold: 0, Color: "#ff0000"}}}
+
+	keys := []string{"testCommand", "minCoverage", "fileMinCoverage", "gitlab", "levels", "quiet", "unknown"}
+	if err := base.MergeKeys(&other, keys...); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if base.TestCommand != "new command" || base.MinCoverage != 80 || base.Levels.String() != "0=#ff0000" {
+		t.Errorf("Merged keys not overridden: %+v", base)
+	}
+
+	if base.OutputFile != "original.svg" {
+		t.Errorf("OutputFile = %q, want it unchanged", base.OutputFile)
+	}
+
+	if base.Quiet || base.GitLab || base.FileMinCoverage != 0 {
+		t.Errorf("Zero values not overridden: %+v", base)
+	}
+}
+
+func TestConfigFileKeys(t *testing.T) {
+	t.Parallel()
+
+	filename := filepath.Join(t.TempDir(), "stampli.toml")
+	if err := os.WriteFile(filename, []byte("quiet = true\noutputFile = \"x.svg\"\n"), 0o644); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	keys, err := ConfigFileKeys(filename)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !slices.Equal(keys, []string{"outputFile", "quiet"}) {
+		t.Errorf("ConfigFileKeys() = %v, want [outputFile quiet]", keys)
+	}
+
+	if _, err = ConfigFileKeys(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected error for a missing file")
+	}
+}
+
+func TestConfigProfiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"stampli.json": `{"outputFile": "badge.svg", "quiet": true, "profiles": {` +
+			`"unit": {"outputFile": "unit.svg", "levels": "=red"}, "e2e": {"badgeType": "tests"}, "off": {"quiet": false}}}`,
+		"nested.json":  `{"profiles": {"unit": {"profiles": {}}}}`,
+		"unknown.yaml": "profiles:\n  unit:\n    outpuFile: unit.svg\n",
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to create config file: %v", err)
+		}
+	}
+
+	cfg, err := LoadConfig(filepath.Join(dir, "stampli.json"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if names := cfg.ProfileNames(); !slices.Equal(names, []string{"e2e", "off", "unit"}) {
+		t.Errorf("ProfileNames() = %v, want [e2e off unit]", names)
+	}
+
+	keys, err := cfg.ApplyProfile("unit")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !slices.Equal(keys, []string{"levels", "outputFile"}) {
+		t.Errorf("Keys = %v, want [levels outputFile]", keys)
+	}
+
+	// Profile paths are relative to the config file too, unset keys are kept.
+	if cfg.OutputFile != filepath.Join(dir, "unit.svg") || cfg.Levels.String() != "0=#e05d44" || !cfg.Quiet {
+		t.Errorf("OutputFile = %q, Levels = %q, Quiet = %v, want the unit profile over the config",
+			cfg.OutputFile, cfg.Levels.String(), cfg.Quiet)
+	}
+
+	// Profiles override with zero values too.
+	if _, err = cfg.ApplyProfile("off"); err != nil || cfg.Quiet {
+		t.Errorf("ApplyProfile(off) = %v, Quiet = %v, want false", err, cfg.Quiet)
+	}
+
+	// Profiles only (re)encode the keys they set, zero values included.
+	js, err := json.Marshal(cfg.Profiles["e2e"])
+	if err != nil || string(js) != `{"badgeType":"tests"}` {
+		t.Errorf("Marshal(e2e) = %s (%v), want {\"badgeType\":\"tests\"}", js, err)
+	}
+
+	if js, err = json.Marshal(cfg.Profiles["off"]); err != nil || string(js) != `{"quiet":false}` {
+		t.Errorf("Marshal(off) = %s (%v), want {\"quiet\":false}", js, err)
+	}
+
+	if _, err = cfg.ApplyProfile("lint"); !errors.Is(err, ErrUnknownProfile) {
+		t.Errorf("Expected error %v, got %v", ErrUnknownProfile, err)
+	}
+
+	if _, err = LoadConfig(filepath.Join(dir, "nested.json")); !errors.Is(err, ErrNestedProfiles) {
+		t.Errorf("Expected error %v, got %v", ErrNestedProfiles, err)
+	}
+
+	if _, err = LoadConfig(filepath.Join(dir, "unknown.yaml")); !errors.Is(err, ErrUnknownKey) ||
+		!strings.HasSuffix(err.Error(), `unknown.yaml:3:5: unknown config key "outpuFile"`) {
+		t.Errorf("Expected a located unknown key error, got %v", err)
+	}
+}
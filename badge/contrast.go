@@ -0,0 +1,109 @@
+package badge
+
+import "fmt"
+
+// MinContrast is the minimum contrast ratio of normal text required by WCAG AA.
+const MinContrast = 4.5
+
+// ContrastIssue is a text and background colors pair of a level
+// that does not meet MinContrast.
+type ContrastIssue struct {
+	Threshold  float64
+	Part       string // Either "text" (value text on Color) or "label" (label text on LabelColor).
+	Foreground string
+	Background string
+	Ratio      float64
+	// SuggestedForeground and SuggestedBackground are adjusted colors
+	// meeting MinContrast, empty if there is none to suggest.
+	SuggestedForeground string
+	SuggestedBackground string
+}
+
+// ContrastRatio returns the WCAG contrast ratio of two colors, from 1 to 21.
+//
+//nolint:mnd // ok
+func ContrastRatio(a, b string) float64 {
+	la, lb := relativeLuminance(a), relativeLuminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// CheckContrast returns the contrast issues of the levels, checking
+// the value text against the level color and the label text against
+// the label color, with the level overrides or their defaults.
+func (l Levels) CheckContrast() (issues []ContrastIssue) {
+	for _, level := range l {
+		colors := l.ColorsFor(level.Threshold, ColorModeDiscrete)
+
+		if issue, ok := checkContrast(level.Threshold, "text", colors.TextColor, colors.Color); ok {
+			for _, fg := range []string{"#000000", "#ffffff"} {
+				if ContrastRatio(fg, colors.Color) >= MinContrast {
+					issue.SuggestedForeground = fg
+					break
+				}
+			}
+
+			issues = append(issues, issue)
+		}
+
+		if issue, ok := checkContrast(level.Threshold, "label", LabelTextColor, colors.LabelColor); ok {
+			issues = append(issues, issue)
+		}
+	}
+
+	return
+}
+
+func checkContrast(threshold float64, part, fg, bg string) (issue ContrastIssue, ok bool) {
+	ratio := ContrastRatio(fg, bg)
+	if ratio >= MinContrast {
+		return
+	}
+
+	issue = ContrastIssue{Threshold: threshold, Part: part, Foreground: fg, Background: bg, Ratio: ratio}
+	issue.SuggestedBackground = adjustContrast(fg, bg)
+
+	return issue, true
+}
+
+// adjustContrast returns bg, darkened or lightened away from fg just enough
+// to meet MinContrast, or an empty string if that is not possible.
+//
+//nolint:mnd // ok
+func adjustContrast(fg, bg string) string {
+	with := 255.0
+	if relativeLuminance(fg) > relativeLuminance(bg) {
+		with = 0
+	}
+
+	for step := 1; step <= 100; step++ {
+		color, err := mix(bg, with, float64(step)/100)
+		if err == nil && ContrastRatio(fg, color) >= MinContrast {
+			return color
+		}
+	}
+
+	return ""
+}
+
+func (i ContrastIssue) String() string {
+	s := fmt.Sprintf("level %s: %s %s on %s has a %.2f:1 contrast ratio (minimum %.1f:1)",
+		formatThreshold(i.Threshold), i.Part, i.Foreground, i.Background, i.Ratio, MinContrast)
+
+	background := "level color"
+	if i.Part == "label" {
+		background = "label color"
+	}
+
+	switch {
+	case i.SuggestedForeground != "" && i.SuggestedBackground != "":
+		s += fmt.Sprintf(", try text color %s or %s %s", i.SuggestedForeground, background, i.SuggestedBackground)
+	case i.SuggestedBackground != "":
+		s += fmt.Sprintf(", try %s %s", background, i.SuggestedBackground)
+	}
+
+	return s
+}
@@ -0,0 +1,91 @@
+package badge
+
+import (
+	"math"
+	"testing"
+)
+
+func TestContrastRatio(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		a, b     string
+		expected float64
+	}{
+		{"#000000", "#ffffff", 21},
+		{"#ffffff", "#000000", 21},
+		{"#fff", "#fff", 1},
+		{"#ffffff", "#44cc11", 2.12},
+		{"#000000", "#44cc11", 9.9},
+		{"#fff", "#555", 7.46},
+	}
+
+	for _, tt := range tests {
+		if got := ContrastRatio(tt.a, tt.b); math.Abs(got-tt.expected) > 0.01 {
+			t.Errorf("ContrastRatio(%s, %s) = %.2f, want %.2f", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
+
+func TestLevelsCheckContrast(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		levels   Levels
+		expected []string
+	}{
+		{
+			name:   "Readable levels",
+			levels: Levels{{Threshold: 85, Color: "#44cc11", TextColor: "#000"}, {Threshold: 0, Color: "#b00020"}},
+		},
+		{
+			name:   "Default text colors",
+			levels: Levels{{Threshold: 85, Color: "#44cc11"}, {Threshold: 50, Color: "#dfb317"}, {Threshold: 0, Color: "#ff0001"}},
+		},
+		{
+			name:   "Low contrast text",
+			levels: Levels{{Threshold: 85, Color: "#44cc11", TextColor: "#fff"}, {Threshold: 0, Color: "#b00020"}},
+			expected: []string{
+				"level 85: text #fff on #44cc11 has a 2.12:1 contrast ratio (minimum 4.5:1), " +
+					"try text color #000000 or level color #2d870b",
+			},
+		},
+		{
+			name:   "Low contrast label",
+			levels: Levels{{Threshold: 0, Color: "#b00020", LabelColor: "#ccc"}},
+			expected: []string{
+				"level 0: label #fff on #ccc has a 1.61:1 contrast ratio (minimum 4.5:1), try label color #767676",
+			},
+		},
+		{
+			name:   "Low contrast text override",
+			levels: Levels{{Threshold: 0, Color: "#777", TextColor: "#888"}},
+			expected: []string{
+				"level 0: text #888 on #777 has a 1.26:1 contrast ratio (minimum 4.5:1), " +
+					"try text color #000000 or level color #212121",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			issues := tt.levels.CheckContrast()
+			if len(issues) != len(tt.expected) {
+				t.Fatalf("CheckContrast() = %v, want %v", issues, tt.expected)
+			}
+
+			for i, issue := range issues {
+				if issue.String() != tt.expected[i] {
+					t.Errorf("CheckContrast()[%d] = %q, want %q", i, issue, tt.expected[i])
+				}
+
+				if issue.SuggestedBackground != "" && ContrastRatio(issue.Foreground, issue.SuggestedBackground) < MinContrast {
+					t.Errorf("Suggested background %s does not meet the minimum contrast", issue.SuggestedBackground)
+				}
+			}
+		})
+	}
+}
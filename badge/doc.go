@@ -0,0 +1,19 @@
+// Package badge implements the stampli coverage badge generator: parsing Go
+// coverage profiles and go test -json output, mapping coverage to colors via
+// Levels and rendering shields.io style SVG badges from (custom) templates.
+//
+// It is the library behind the stampli command, so that other tools can
+// generate badges without shelling out:
+//
+//	cov, err := badge.ParseCoverageFile("coverage.out")
+//	if err != nil {
+//		return err
+//	}
+//
+//	cfg, err := badge.LoadConfig("stampli.json")
+//	if err != nil {
+//		return err
+//	}
+//
+//	svg, err := badge.Render(badge.Options{Coverage: cov, Levels: cfg.Levels})
+package badge
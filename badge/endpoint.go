@@ -0,0 +1,31 @@
+package badge
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Endpoint is a shields.io endpoint badge, see https://shields.io/badges/endpoint-badge.
+type Endpoint struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+	LabelColor    string `json:"labelColor,omitempty"`
+}
+
+// RenderEndpoint renders a badge according to opts as shields.io endpoint
+// JSON, for rendering it (or a differently styled one) via shields.io.
+func RenderEndpoint(opts Options) ([]byte, error) {
+	data, err := newData(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	e := Endpoint{SchemaVersion: 1, Label: data.Label, Message: data.Value, Color: strings.TrimPrefix(data.Color, "#")}
+	if data.LabelColor != DefaultLabelColor {
+		e.LabelColor = strings.TrimPrefix(data.LabelColor, "#")
+	}
+
+	return json.Marshal(e) //nolint:wrapcheck // ok
+}
@@ -0,0 +1,49 @@
+package badge
+
+import "testing"
+
+func TestRenderEndpoint(t *testing.T) {
+	t.Parallel()
+
+	levels, err := NewLevels(Level{Threshold: 80, Color: "#44cc11"}, Level{Color: "#ff0001", LabelColor: "#333"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			name: "Coverage",
+			opts: Options{Coverage: 85.42, Levels: levels},
+			want: `{"schemaVersion":1,"label":"coverage","message":"85.4%","color":"44cc11"}`,
+		},
+		{
+			name: "Label color",
+			opts: Options{Coverage: 12, Levels: levels},
+			want: `{"schemaVersion":1,"label":"coverage","message":"12.0%","color":"ff0001","labelColor":"333"}`,
+		},
+		{
+			name: "Tests",
+			opts: Options{Type: TypeTests, Tests: &TestStats{Passed: 3}, Levels: levels},
+			want: `{"schemaVersion":1,"label":"tests","message":"3 passed","color":"44cc11"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := RenderEndpoint(tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("RenderEndpoint() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
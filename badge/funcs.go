@@ -0,0 +1,140 @@
+package badge
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"text/template"
+)
+
+var errNotANumber = errors.New("not a number")
+
+// funcMap returns the helper functions available to the badge templates.
+// The levels and color mode are used by levelColor, to map arbitrary values
+// to colors.
+func funcMap(levels Levels, colorMode string) template.FuncMap {
+	return template.FuncMap{
+		"textWidth":    TextWidth,
+		"lighten":      lighten,
+		"darken":       darken,
+		"contrast":     contrast,
+		"formatNumber": formatNumber,
+		"xmlEscape":    xmlEscape,
+		"round":        round,
+		"add":          arith(func(a, b float64) float64 { return a + b }),
+		"sub":          arith(func(a, b float64) float64 { return a - b }),
+		"mul":          arith(func(a, b float64) float64 { return a * b }),
+		"div":          arith(func(a, b float64) float64 { return a / b }),
+		"toJSON":       toJSON,
+		"levelColor": func(v any) (string, error) {
+			f, err := toFloat(v)
+			return levels.ColorFor(f, colorMode), err
+		},
+	}
+}
+
+// lighten mixes the color with white, by the given amount (0..1).
+func lighten(color string, amount float64) (string, error) {
+	return mix(color, 255, amount) //nolint:mnd // ok
+}
+
+// darken mixes the color with black, by the given amount (0..1).
+func darken(color string, amount float64) (string, error) {
+	return mix(color, 0, amount)
+}
+
+// contrast returns the text color (black or white) best readable on color.
+func contrast(color string) (string, error) {
+	color, err := ParseColor(color)
+	if err != nil {
+		return "", err
+	}
+
+	return OptimalTextColor(color), nil
+}
+
+func mix(color string, with, amount float64) (string, error) {
+	color, err := ParseColor(color)
+	if err != nil {
+		return "", err
+	}
+
+	amount = math.Max(0, math.Min(1, amount))
+	r, g, b := hexToRGB(color)
+	f := func(c int64) int64 { return int64(math.Round(float64(c) + (with-float64(c))*amount)) }
+
+	return rgbToHex(f(r), f(g), f(b)), nil
+}
+
+// formatNumber formats a number (or a numeric string, such as .Coverage)
+// using a printf style format, i.e. formatNumber "%.0f" .Coverage.
+func formatNumber(format string, v any) (string, error) {
+	f, err := toFloat(v)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(format, f), nil
+}
+
+// xmlEscape escapes s for safe inclusion in XML text and attributes.
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+
+	xml.EscapeText(&buf, []byte(s)) //nolint:errcheck,gosec // bytes.Buffer never fails
+
+	return buf.String()
+}
+
+// toJSON encodes v as JSON, i.e. for templates generating JSON files.
+func toJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err //nolint:wrapcheck // ok
+}
+
+// round rounds a number to the given number of decimal places.
+func round(v any, places int) (float64, error) {
+	f, err := toFloat(v)
+	p := math.Pow10(places)
+
+	return math.Round(f*p) / p, err
+}
+
+func arith(op func(a, b float64) float64) func(a, b any) (float64, error) {
+	return func(a, b any) (float64, error) {
+		x, err := toFloat(a)
+		if err != nil {
+			return 0, err
+		}
+
+		y, err := toFloat(b)
+
+		return op(x, y), err
+	}
+}
+
+func toFloat(v any) (float64, error) {
+	switch x := v.(type) {
+	case float64:
+		return x, nil
+	case float32:
+		return float64(x), nil
+	case int:
+		return float64(x), nil
+	case int64:
+		return float64(x), nil
+	case string:
+		f, err := strconv.ParseFloat(x, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", errNotANumber, x)
+		}
+
+		return f, nil
+	default:
+		return 0, fmt.Errorf("%w: %v (%T)", errNotANumber, v, v)
+	}
+}
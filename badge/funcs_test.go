@@ -0,0 +1,69 @@
+package badge
+
+import (
+	"testing"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	t.Parallel()
+
+	levels := Levels{{Threshold: 70, Color: "#44cc11"}, {Threshold: 40, Color: "#dfb317"}, {Threshold: 0, Color: "#ff0001"}}
+
+	tests := []struct {
+		name        string
+		template    string
+		expected    string
+		shouldError bool
+	}{
+		{"textWidth", `{{textWidth "coverage"}}`, "51", false},
+		{"lighten", `{{lighten "#000000" 0.5}}`, "#808080", false},
+		{"lighten short hex", `{{lighten "#f00" 1}}`, "#ffffff", false},
+		{"lighten clamps amount", `{{lighten "#44cc11" -1}}`, "#44cc11", false},
+		{"lighten named color", `{{lighten "red" 0.5}}`, "#f0aea2", false},
+		{"lighten invalid color", `{{lighten "nope" 0.5}}`, "", true},
+		{"darken", `{{darken "#ffffff" 0.25}}`, "#bfbfbf", false},
+		{"darken invalid color", `{{darken "#gg0000" 0.5}}`, "", true},
+		{"contrast dark", `{{contrast "#000080"}}`, "#ffffff", false},
+		{"contrast light", `{{contrast "#ffff00"}}`, "#000000", false},
+		{"contrast rgb color", `{{contrast "rgb(255 255 0)"}}`, "#000000", false},
+		{"contrast invalid color", `{{contrast "yelow"}}`, "", true},
+		{"contrast of level color", `{{contrast .Color}}`, "#000000", false},
+		{"formatNumber from string", `{{formatNumber "%.0f" .Coverage}}`, "86", false},
+		{"formatNumber from int", `{{formatNumber "%05.1f" 7}}`, "007.0", false},
+		{"formatNumber invalid", `{{formatNumber "%.0f" "abc"}}`, "", true},
+		{"xmlEscape", `{{xmlEscape "a<b & \"c\""}}`, "a&lt;b &amp; &#34;c&#34;", false},
+		{"round", `{{round 85.456 1}}`, "85.5", false},
+		{"round string", `{{round .Coverage 0}}`, "86", false},
+		{"round invalid", `{{round true 0}}`, "", true},
+		{"arithmetic", `{{add 1 (mul 2 (sub 10 (div 9 3)))}}`, "15", false},
+		{"arithmetic with width", `{{add .LabelWidth (textWidth "x")}}`, "68", false},
+		{"arithmetic invalid", `{{add "x" 1}}`, "", true},
+		{"arithmetic invalid second operand", `{{add 1 "x"}}`, "", true},
+		{"levelColor", `{{levelColor 55}}`, "#dfb317", false},
+		{"levelColor string", `{{levelColor "99.5"}}`, "#44cc11", false},
+		{"levelColor invalid", `{{levelColor "x"}}`, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := Render(Options{Coverage: 85.5, Levels: levels, Template: tt.template})
+			if tt.shouldError {
+				if err == nil {
+					t.Errorf("Expected error but got %q", result)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if string(result) != tt.expected {
+				t.Errorf("Render(%s) = %q, want %q", tt.template, result, tt.expected)
+			}
+		})
+	}
+}
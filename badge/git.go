@@ -0,0 +1,137 @@
+package badge
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// GitInfo describes the git commit a badge was generated for.
+type GitInfo struct {
+	Commit      string
+	ShortCommit string
+	Branch      string // Empty for a detached HEAD.
+}
+
+// ReadGitInfo returns the git commit and branch of the repository containing
+// dir. It uses git itself when available, falling back to reading the .git
+// directory, and returns an empty GitInfo outside of a repository.
+func ReadGitInfo(dir string) (info GitInfo) {
+	if out, err := gitOutput(dir, "rev-parse", "HEAD", "--abbrev-ref", "HEAD"); err == nil {
+		if fields := strings.Fields(out); len(fields) == 2 { //nolint:mnd // ok
+			info.Commit, info.Branch = fields[0], fields[1]
+		}
+	} else {
+		info = readGitDir(dir)
+	}
+
+	if info.Branch == "HEAD" {
+		info.Branch = ""
+	}
+
+	info.ShortCommit = info.Commit[:min(len(info.Commit), 7)] //nolint:mnd // ok
+
+	return
+}
+
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...) //nolint:noctx // ok
+	cmd.Dir = dir
+
+	out, err := cmd.Output()
+
+	return string(out), err //nolint:wrapcheck // ok
+}
+
+// readGitDir reads HEAD (and the ref it points to) from the .git directory
+// found in dir or any of its parents.
+func readGitDir(dir string) (info GitInfo) {
+	gitDir := findGitDir(dir)
+	if gitDir == "" {
+		return
+	}
+
+	head, err := os.ReadFile(filepath.Join(gitDir, "HEAD")) //nolint:gosec // ok
+	if err != nil {
+		return
+	}
+
+	ref, ok := strings.CutPrefix(strings.TrimSpace(string(head)), "ref: ")
+	if !ok {
+		info.Commit = ref // Detached HEAD.
+		return
+	}
+
+	info.Branch = strings.TrimPrefix(ref, "refs/heads/")
+
+	if sha, err := os.ReadFile(filepath.Join(gitDir, filepath.FromSlash(ref))); err == nil { //nolint:gosec // ok
+		info.Commit = strings.TrimSpace(string(sha))
+		return
+	}
+
+	info.Commit = packedRef(gitDir, ref)
+
+	return
+}
+
+// findGitDir returns the git directory of the repository containing dir,
+// following the "gitdir: ..." indirection used by worktrees and submodules.
+func findGitDir(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+
+	for {
+		gitPath := filepath.Join(dir, ".git")
+
+		if fi, err := os.Stat(gitPath); err == nil {
+			if fi.IsDir() {
+				return gitPath
+			}
+
+			data, err := os.ReadFile(gitPath) //nolint:gosec // ok
+			if err != nil {
+				return ""
+			}
+
+			target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+			if !ok {
+				return ""
+			}
+
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(dir, target)
+			}
+
+			return target
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+
+		dir = parent
+	}
+}
+
+func packedRef(gitDir, ref string) string {
+	f, err := os.Open(filepath.Join(gitDir, "packed-refs")) //nolint:gosec // ok
+	if err != nil {
+		return ""
+	}
+
+	defer f.Close() //nolint:errcheck // read only
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if sha, name, ok := strings.Cut(scanner.Text(), " "); ok && name == ref {
+			return sha
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,134 @@
+package badge
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+const testSHA = "0123456789abcdef0123456789abcdef01234567"
+
+func TestReadGitDir(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		files    map[string]string
+		subdir   string
+		expected GitInfo
+	}{
+		{
+			name: "Branch with loose ref",
+			files: map[string]string{
+				".git/HEAD":            "ref: refs/heads/main\n",
+				".git/refs/heads/main": testSHA + "\n",
+			},
+			expected: GitInfo{Commit: testSHA, Branch: "main"},
+		},
+		{
+			name: "Branch with packed ref, from a subdirectory",
+			files: map[string]string{
+				".git/HEAD":        "ref: refs/heads/feature/x\n",
+				".git/packed-refs": "# pack-refs with: peeled fully-peeled sorted\n" + testSHA + " refs/heads/feature/x\n",
+				"pkg/sub/file.go":  "package sub\n",
+			},
+			subdir:   "pkg/sub",
+			expected: GitInfo{Commit: testSHA, Branch: "feature/x"},
+		},
+		{
+			name: "Branch without commits",
+			files: map[string]string{
+				".git/HEAD": "ref: refs/heads/main\n",
+			},
+			expected: GitInfo{Branch: "main"},
+		},
+		{
+			name: "Detached HEAD",
+			files: map[string]string{
+				".git/HEAD": testSHA + "\n",
+			},
+			expected: GitInfo{Commit: testSHA},
+		},
+		{
+			name: "Worktree gitdir indirection",
+			files: map[string]string{
+				"wt/.git":                     "gitdir: ../main/.git/worktrees/wt\n",
+				"main/.git/worktrees/wt/HEAD": testSHA + "\n",
+			},
+			subdir:   "wt",
+			expected: GitInfo{Commit: testSHA},
+		},
+		{
+			name: "Invalid .git file",
+			files: map[string]string{
+				".git": "garbage\n",
+			},
+		},
+		{
+			name: "Missing HEAD",
+			files: map[string]string{
+				".git/config": "",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+
+			for name, content := range tt.files {
+				path := filepath.Join(dir, filepath.FromSlash(name))
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatalf("Failed to create directory: %v", err)
+				}
+
+				if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+					t.Fatalf("Failed to create file: %v", err)
+				}
+			}
+
+			if got := readGitDir(filepath.Join(dir, tt.subdir)); got != tt.expected {
+				t.Errorf("readGitDir() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestReadGitInfo(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Outside of a repository", func(t *testing.T) {
+		t.Parallel()
+
+		if info := ReadGitInfo(t.TempDir()); info != (GitInfo{}) {
+			t.Errorf("ReadGitInfo() = %+v, want empty", info)
+		}
+	})
+
+	t.Run("Repository", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not available")
+		}
+
+		dir := t.TempDir()
+
+		for _, args := range [][]string{
+			{"init", "-q", "-b", "trunk"},
+			{"-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "--allow-empty", "-m", "init"},
+		} {
+			if out, err := gitOutput(dir, args...); err != nil {
+				t.Fatalf("git %v failed: %v\n%s", args, err, out)
+			}
+		}
+
+		info := ReadGitInfo(dir)
+		if info.Branch != "trunk" || len(info.Commit) != 40 || info.ShortCommit != info.Commit[:7] {
+			t.Errorf("ReadGitInfo() = %+v", info)
+		}
+	})
+}
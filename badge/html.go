@@ -0,0 +1,163 @@
+package badge
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+//go:embed html-report.tmpl
+var htmlReportTemplate string
+
+var htmlReport = template.Must(template.New("report").Parse(htmlReportTemplate)) //nolint:gochecknoglobals // ok
+
+// HTMLReport is a static HTML coverage report of a Profile: an index page with
+// the coverage of each package and file, as bars of their Levels colors, and a
+// page per file, with its covered and uncovered lines highlighted.
+type HTMLReport struct {
+	Profile   *Profile
+	Levels    Levels
+	ColorMode string
+	// Badge is the SVG badge shown at the top of the pages, if any.
+	Badge string
+	// Module is the module path, stripped from the file paths of the profile
+	// to read the sources relative to Source (the current directory if empty).
+	Module string
+	Source string
+}
+
+type htmlRow struct {
+	Coverage
+
+	Color string
+	Base  string // The file base name.
+	Page  string // The file page, relative to the index.
+}
+
+type htmlPackage struct {
+	htmlRow
+
+	Files []htmlRow
+}
+
+type htmlLine struct {
+	Number int
+	Text   string
+	Class  string // Either "cov", "uncov" or empty, for lines without statements.
+}
+
+// Write writes the report to dir, creating it if needed: index.html and
+// the file pages, under files/, mirroring the module layout.
+func (r HTMLReport) Write(dir string) error {
+	badge := template.HTML(r.Badge) //nolint:gosec // our own rendered SVG
+
+	files := map[string][]Coverage{}
+	for _, file := range r.Profile.Files() {
+		files[path.Dir(file.Name)] = append(files[path.Dir(file.Name)], file)
+	}
+
+	covered, total := r.Profile.Statements()
+	index := struct {
+		Badge    template.HTML
+		Packages []htmlPackage
+		Total    htmlRow
+	}{Badge: badge, Total: r.row(Coverage{Name: "Total", Covered: covered, Statements: total})}
+
+	for _, pkg := range r.Profile.Packages() {
+		p := htmlPackage{htmlRow: r.row(pkg)}
+		for _, file := range files[pkg.Name] {
+			p.Files = append(p.Files, r.row(file))
+		}
+
+		index.Packages = append(index.Packages, p)
+	}
+
+	if err := writeHTML(filepath.Join(dir, "index.html"), "index", index); err != nil {
+		return err
+	}
+
+	hits := map[string]map[int]int{}
+	for _, file := range r.Profile.lineHits() {
+		hits[file.name] = file.hits
+	}
+
+	for _, pkg := range index.Packages {
+		for _, file := range pkg.Files {
+			page := struct {
+				htmlRow
+
+				Badge template.HTML
+				Index string
+				Lines []htmlLine
+			}{htmlRow: file, Badge: badge, Index: strings.Repeat("../", strings.Count(file.Page, "/")) + "index.html"}
+
+			page.Lines = r.sourceLines(file.Name, hits[file.Name])
+
+			if err := writeHTML(filepath.Join(dir, filepath.FromSlash(file.Page)), "file", page); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r HTMLReport) row(c Coverage) htmlRow {
+	name := r.relative(c.Name)
+
+	return htmlRow{Coverage: c, Color: r.Levels.ColorFor(c.Percent(), r.ColorMode), Base: path.Base(name), Page: "files/" + name + ".html"}
+}
+
+// relative returns the file path of the profile, relative to the module root.
+func (r HTMLReport) relative(name string) string {
+	if r.Module == "" {
+		return name
+	}
+
+	return strings.TrimPrefix(name, r.Module+"/")
+}
+
+// sourceLines returns the lines of the given source file, classified by
+// their hits, or none if the file cannot be read.
+func (r HTMLReport) sourceLines(name string, hits map[int]int) (lines []htmlLine) {
+	data, err := os.ReadFile(filepath.Join(r.Source, filepath.FromSlash(r.relative(name))))
+	if err != nil {
+		return nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := htmlLine{Number: n, Text: strings.ReplaceAll(scanner.Text(), "\t", "    ")}
+
+		if count, ok := hits[n]; ok {
+			line.Class = "uncov"
+			if count > 0 {
+				line.Class = "cov"
+			}
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+func writeHTML(filename, name string, data any) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil { //nolint:mnd // ok
+		return fmt.Errorf("failed to create the report directory: %w", err)
+	}
+
+	var buf bytes.Buffer
+
+	if err := htmlReport.ExecuteTemplate(&buf, name, data); err != nil {
+		return fmt.Errorf("failed to render %s: %w", filename, err)
+	}
+
+	return os.WriteFile(filename, buf.Bytes(), 0o644) //nolint:gosec,mnd,wrapcheck // ok
+}
@@ -0,0 +1,83 @@
+package badge
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHTMLReportWrite(t *testing.T) {
+	t.Parallel()
+
+	src := t.TempDir()
+	source := "package a\n\nfunc A(x int) int {\n\tif x > 0 {\n\t\treturn x\n\t}\n\n\treturn -x\n}\n"
+
+	if err := os.MkdirAll(filepath.Join(src, "a"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(src, "a", "a.go"), []byte(source), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := ParseProfile(strings.NewReader("mode: set\n" +
+		"m/a/a.go:3.19,4.11 1 1\nm/a/a.go:4.11,6.3 1 1\nm/a/a.go:8.2,8.11 1 0\nm/b/b.go:1.1,2.2 1 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	levels, err := NewLevels(Level{Threshold: 50, Color: "#44cc11"}, Level{Color: "#ff0001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "report")
+	r := HTMLReport{Profile: p, Levels: levels, Badge: `<svg id="badge"></svg>`, Module: "m", Source: src}
+
+	if err = r.Write(dir); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{
+			file: "index.html",
+			want: []string{
+				`<p><svg id="badge"></svg></p>`,
+				`<h1>Coverage report: 50.0%</h1>`,
+				`<tr><th>m/a</th><td><div class="bar"><div style="width: 66.7%; background: #44cc11"></div></div></td>`,
+				`<a href="files/a/a.go.html">a.go</a>`,
+				`<div style="width: 0.0%; background: #ff0001">`,
+				`<td class="num">2/4</td>`,
+			},
+		},
+		{
+			file: filepath.Join("files", "a", "a.go.html"),
+			want: []string{
+				`<a href="../../index.html">`,
+				`<h1>m/a/a.go: 66.7%</h1>`,
+				`<span><i>1</i>package a</span>`,
+				`<span class="cov"><i>3</i>func A(x int) int {</span>`,
+				`<span class="uncov"><i>8</i>    return -x</span>`,
+				`<span><i>9</i>}</span>`,
+			},
+		},
+		{file: filepath.Join("files", "b", "b.go.html"), want: []string{`<p>The source file is not available.</p>`}},
+	}
+
+	for _, tt := range tests {
+		got, err := os.ReadFile(filepath.Join(dir, tt.file))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", tt.file, err)
+		}
+
+		for _, want := range tt.want {
+			if !strings.Contains(string(got), want) {
+				t.Errorf("%s should contain %q, got:\n%s", tt.file, want, got)
+			}
+		}
+	}
+}
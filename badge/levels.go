@@ -0,0 +1,335 @@
+package badge
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Levels represents coverage thresholds and their corresponding colors,
+// ordered by descending threshold. Build them via NewLevels or Set, which
+// sort and validate them.
+type Levels []Level
+
+// Level is a single coverage threshold and its color, with optional
+// overrides of the value text color and of the label background color.
+type Level struct {
+	Threshold  float64
+	Color      string
+	TextColor  string `json:",omitempty"` // Defaults to the OptimalTextColor of Color.
+	LabelColor string `json:",omitempty"` // Defaults to DefaultLabelColor.
+}
+
+// DefaultLabelColor is the label background color used
+// when the level does not override it.
+const DefaultLabelColor = "#555"
+
+// LabelTextColor is the label text color of the built-in templates.
+const LabelTextColor = "#fff"
+
+// Color modes, see Levels.ColorFor.
+const (
+	ColorModeDiscrete = "discrete"
+	ColorModeGradient = "gradient"
+)
+
+// Errors returned when parsing Levels.
+var (
+	ErrInvalidLevelNumber = errors.New("invalid level number")
+	ErrInvalidColor       = errors.New("invalid color")
+	ErrInvalidLevelFormat = errors.New("invalid level format")
+	ErrLevelOutOfRange    = errors.New("level out of the [0, 100] range")
+	ErrDuplicateLevel     = errors.New("duplicate level")
+	ErrMissingDefault     = errors.New("missing default level (0=color or =color)")
+	ErrUnsortedLevels     = errors.New("levels not sorted by descending threshold")
+)
+
+// NewLevels returns the given levels sorted by descending threshold,
+// or an error if they are not valid (see Levels.Validate).
+func NewLevels(levels ...Level) (Levels, error) {
+	l := Levels(slices.Clone(levels))
+	slices.SortStableFunc(l, func(a, b Level) int { return cmp.Compare(b.Threshold, a.Threshold) })
+
+	if err := l.Validate(); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// Validate checks that the levels are sorted by descending threshold, within
+// the [0, 100] range, without duplicates and that they include a default (0)
+// level, which guarantees that every coverage value maps to a level.
+func (l Levels) Validate() error {
+	for i, level := range l {
+		if level.Threshold < 0 || level.Threshold > 100 {
+			return fmt.Errorf("%w: %s", ErrLevelOutOfRange, formatThreshold(level.Threshold))
+		}
+
+		if i == 0 {
+			continue
+		}
+
+		switch prev := l[i-1].Threshold; {
+		case prev == level.Threshold:
+			return fmt.Errorf("%w: %s", ErrDuplicateLevel, formatThreshold(level.Threshold))
+		case prev < level.Threshold:
+			return ErrUnsortedLevels
+		}
+	}
+
+	if len(l) == 0 || l[len(l)-1].Threshold != 0 {
+		return ErrMissingDefault
+	}
+
+	return nil
+}
+
+// String returns the levels in the format accepted by Set, by ascending threshold.
+func (l *Levels) String() string {
+	parts := make([]string, 0, len(*l))
+
+	for _, level := range slices.Backward(*l) {
+		colors := strings.TrimRight(level.Color+"/"+level.TextColor+"/"+level.LabelColor, "/")
+		parts = append(parts, formatThreshold(level.Threshold)+"="+colors)
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func formatThreshold(threshold float64) string {
+	return strconv.FormatFloat(threshold, 'f', -1, 64)
+}
+
+// Set implements flag.Value interface.
+// Accepts format like "90=#00F,80=#09F,60=#F0F,=#F00" or "0=#F00".
+// Colors may be given in any of the forms accepted by ParseColor
+// (i.e. "90=brightgreen,70=rgb(223,179,23),=hsl(0,100%,50%)").
+// Each color may be followed by the text and label colors overrides,
+// separated by slashes (i.e. "90=#44cc11/#000/#333" or "90=#44cc11//#333").
+// An empty value resets the levels, otherwise they must be valid
+// (see Levels.Validate).
+func (l *Levels) Set(value string) error {
+	levels := []Level{}
+
+	for _, part := range splitTopLevel(value, ',') {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("%w: %s (expected format: level=color)", ErrInvalidLevelFormat, part)
+		}
+
+		var (
+			level float64
+			err   error
+		)
+
+		levelStr := strings.TrimSpace(kv[0])
+		if levelStr == "" {
+			level = 0 // Default level.
+		} else {
+			level, err = strconv.ParseFloat(levelStr, 64)
+			if err != nil {
+				return fmt.Errorf("%w: %s", ErrInvalidLevelNumber, levelStr)
+			}
+		}
+
+		colors := splitTopLevel(kv[1], '/')
+		if len(colors) > 3 { //nolint:mnd // ok
+			return fmt.Errorf("%w: %s (expected format: level=color[/text[/label]])", ErrInvalidLevelFormat, part)
+		}
+
+		lvl, err := newLevel(level, append(colors, make([]string, 3-len(colors))...))
+		if err != nil {
+			return err
+		}
+
+		levels = append(levels, lvl)
+	}
+
+	return l.set(levels)
+}
+
+func (l *Levels) set(levels []Level) error {
+	if len(levels) == 0 {
+		*l = nil
+		return nil
+	}
+
+	sorted, err := NewLevels(levels...)
+	if err != nil {
+		return err
+	}
+
+	*l = sorted
+
+	return nil
+}
+
+// newLevel returns the level of the given threshold and (color, text color,
+// label color) colors, the latter two being optional.
+func newLevel(threshold float64, colors []string) (level Level, err error) {
+	for i, color := range colors {
+		if i > 0 && strings.TrimSpace(color) == "" {
+			continue
+		}
+
+		if colors[i], err = ParseColor(color); err != nil {
+			return
+		}
+	}
+
+	return Level{Threshold: threshold, Color: colors[0], TextColor: colors[1], LabelColor: colors[2]}, nil
+}
+
+// Sorted returns a copy of the levels, ordered by descending threshold.
+func (l *Levels) Sorted() []Level {
+	return slices.Clone(*l)
+}
+
+// splitTopLevel splits s on the sep characters that are not inside parentheses,
+// so that colors such as rgb(1, 2, 3) or rgb(1 2 3 / 50%) are kept in one piece.
+func splitTopLevel(s string, sep rune) (parts []string) {
+	depth, start := 0, 0
+
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case sep:
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
+// LevelFor returns the level the given coverage percentage falls in.
+func (l *Levels) LevelFor(coverage float64) Level {
+	// Find the first level that coverage meets or exceeds.
+	for _, level := range *l {
+		if coverage >= level.Threshold {
+			return level
+		}
+	}
+
+	// Ultimate fallback, for (invalid) levels without a default.
+	return Level{Color: "#ff0001"}
+}
+
+// GetColorForCoverage returns the appropriate color for the given coverage percentage.
+func (l *Levels) GetColorForCoverage(coverage float64) string {
+	return l.LevelFor(coverage).Color
+}
+
+// GetGradientColorForCoverage returns the color for the given coverage
+// percentage, interpolated (in the OKLab color space) between the colors of
+// the levels just below and above it. Exact thresholds keep their own color.
+func (l *Levels) GetGradientColorForCoverage(coverage float64) string {
+	for i, level := range *l {
+		if coverage < level.Threshold {
+			continue
+		}
+
+		if i == 0 || coverage == level.Threshold {
+			return level.Color
+		}
+
+		next := (*l)[i-1]
+		t := (coverage - level.Threshold) / (next.Threshold - level.Threshold)
+
+		return hexToOklab(level.Color).lerp(hexToOklab(next.Color), t).hex()
+	}
+
+	return l.GetColorForCoverage(coverage)
+}
+
+// ColorFor returns the color for the given coverage percentage, using
+// either the discrete (default) or the gradient color mode.
+func (l *Levels) ColorFor(coverage float64, mode string) string {
+	if mode == ColorModeGradient {
+		return l.GetGradientColorForCoverage(coverage)
+	}
+
+	return l.GetColorForCoverage(coverage)
+}
+
+// ColorsFor returns the badge colors for the given coverage percentage: the
+// value color as per ColorFor and the text and label colors of its level,
+// or their defaults.
+func (l *Levels) ColorsFor(coverage float64, mode string) Colors {
+	level := l.LevelFor(coverage)
+	c := Colors{Color: l.ColorFor(coverage, mode), TextColor: level.TextColor, LabelColor: level.LabelColor}
+
+	if c.TextColor == "" {
+		c.TextColor = OptimalTextColor(c.Color)
+	}
+
+	if c.LabelColor == "" {
+		c.LabelColor = DefaultLabelColor
+	}
+
+	return c
+}
+
+//nolint:wrapcheck // ok
+func (l Levels) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.String())
+}
+
+// UnmarshalJSON accepts either the string form of Set or the expanded
+// list of objects form, i.e. [{"threshold": 85, "color": "#44cc11",
+// "textColor": "#000", "labelColor": "#333"}, {"color": "#ff0001"}].
+//
+//nolint:wrapcheck // ok
+func (l *Levels) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || trimmed[0] != '[' {
+		var str string
+		if err := json.Unmarshal(data, &str); err != nil {
+			return err
+		}
+
+		return l.Set(str)
+	}
+
+	var items []struct {
+		Threshold  float64 `json:"threshold"`
+		Color      string  `json:"color"`
+		TextColor  string  `json:"textColor"`
+		LabelColor string  `json:"labelColor"`
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&items); err != nil {
+		return err
+	}
+
+	levels := make([]Level, 0, len(items))
+
+	for _, item := range items {
+		level, err := newLevel(item.Threshold, []string{item.Color, item.TextColor, item.LabelColor})
+		if err != nil {
+			return err
+		}
+
+		levels = append(levels, level)
+	}
+
+	return l.set(levels)
+}
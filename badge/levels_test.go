@@ -0,0 +1,782 @@
+package badge
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestLevelsString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		levels   Levels
+		expected string
+	}{
+		{
+			name:     "Empty levels",
+			levels:   Levels{},
+			expected: "",
+		},
+		{
+			name:     "Single level",
+			levels:   Levels{{Threshold: 80, Color: "#00ff00"}},
+			expected: "80=#00ff00",
+		},
+		{
+			name:     "Multiple levels sorted",
+			levels:   Levels{{Threshold: 90, Color: "#00ff00"}, {Threshold: 70, Color: "#ffff00"}, {Threshold: 0, Color: "#ff0000"}},
+			expected: "0=#ff0000,70=#ffff00,90=#00ff00",
+		},
+		{
+			name:     "Decimal levels",
+			levels:   Levels{{Threshold: 85.5, Color: "#00ff00"}, {Threshold: 72.3, Color: "#ffff00"}},
+			expected: "72.3=#ffff00,85.5=#00ff00",
+		},
+		{
+			name:     "Text and label colors",
+			levels:   Levels{{Threshold: 90, Color: "#0f0", LabelColor: "#333"}, {Threshold: 0, Color: "#f00", TextColor: "#fff"}},
+			expected: "0=#f00/#fff,90=#0f0//#333",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := tt.levels.String()
+			if result != tt.expected {
+				t.Errorf("String() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLevelsSet(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		input       string
+		expected    Levels
+		shouldError bool
+	}{
+		{
+			name:        "Valid single level",
+			input:       "0=#00ff00",
+			expected:    Levels{{Threshold: 0, Color: "#00ff00"}},
+			shouldError: false,
+		},
+		{
+			name:        "Valid multiple levels",
+			input:       "90=#00ff00,70=#ffff00,0=#ff0000",
+			expected:    Levels{{Threshold: 90, Color: "#00ff00"}, {Threshold: 70, Color: "#ffff00"}, {Threshold: 0, Color: "#ff0000"}},
+			shouldError: false,
+		},
+		{
+			name:        "Valid with empty default level",
+			input:       "90=#00ff00,=#ff0000",
+			expected:    Levels{{Threshold: 90, Color: "#00ff00"}, {Threshold: 0, Color: "#ff0000"}},
+			shouldError: false,
+		},
+		{
+			name:        "Valid 3-character hex colors",
+			input:       "80=#0f0,60=#ff0,=#f00",
+			expected:    Levels{{Threshold: 80, Color: "#0f0"}, {Threshold: 60, Color: "#ff0"}, {Threshold: 0, Color: "#f00"}},
+			shouldError: false,
+		},
+		{
+			name:        "Valid with spaces",
+			input:       " 80 = #00ff00 , 70 = #ffff00 , = #ff0000 ",
+			expected:    Levels{{Threshold: 80, Color: "#00ff00"}, {Threshold: 70, Color: "#ffff00"}, {Threshold: 0, Color: "#ff0000"}},
+			shouldError: false,
+		},
+		{
+			name:        "Unordered levels",
+			input:       "=#ff0000,90=#00ff00,70=#ffff00",
+			expected:    Levels{{Threshold: 90, Color: "#00ff00"}, {Threshold: 70, Color: "#ffff00"}, {Threshold: 0, Color: "#ff0000"}},
+			shouldError: false,
+		},
+		{
+			name:        "Empty string",
+			input:       "",
+			expected:    nil,
+			shouldError: false,
+		},
+		{
+			name:        "Only commas",
+			input:       ",,",
+			expected:    nil,
+			shouldError: false,
+		},
+		{
+			name:        "Invalid format - no equals",
+			input:       "80#00ff00",
+			expected:    nil,
+			shouldError: true,
+		},
+		{
+			name:        "Invalid format - multiple equals",
+			input:       "80=#00ff00=extra",
+			expected:    nil,
+			shouldError: true,
+		},
+		{
+			name:        "Invalid level number",
+			input:       "abc=#00ff00",
+			expected:    nil,
+			shouldError: true,
+		},
+		{
+			name:        "Invalid hex color - no hash",
+			input:       "80=00ff00",
+			expected:    nil,
+			shouldError: true,
+		},
+		{
+			name:        "Invalid hex color - wrong length",
+			input:       "80=#00ff",
+			expected:    nil,
+			shouldError: true,
+		},
+		{
+			name:        "Invalid hex color - invalid characters",
+			input:       "80=#00gg00",
+			expected:    nil,
+			shouldError: true,
+		},
+		{
+			name:     "Named and functional colors",
+			input:    "90=brightgreen,70=rgb(223, 179, 23),50=hsl(33 100% 50%),=DarkRed",
+			expected: Levels{{Threshold: 90, Color: "#44cc11"}, {Threshold: 70, Color: "#dfb317"}, {Threshold: 50, Color: "#ff8c00"}, {Threshold: 0, Color: "#8b0000"}},
+		},
+		{
+			name:     "8-digit hex color",
+			input:    "80=#00ff0080,0=#ff0000",
+			expected: Levels{{Threshold: 80, Color: "#00ff0080"}, {Threshold: 0, Color: "#ff0000"}},
+		},
+		{
+			name:        "Invalid color name",
+			input:       "80=blurple",
+			expected:    nil,
+			shouldError: true,
+		},
+		{
+			name:        "Decimal levels",
+			input:       "85.5=#00ff00,72.3=#ffff00,0=#ff0000",
+			expected:    Levels{{Threshold: 85.5, Color: "#00ff00"}, {Threshold: 72.3, Color: "#ffff00"}, {Threshold: 0, Color: "#ff0000"}},
+			shouldError: false,
+		},
+		{
+			name:  "Text and label colors",
+			input: "90=#00ff00/black/rgb(51 51 51 / 50%),70=#ffff00//#333,=#ff0000/#fff",
+			expected: Levels{
+				{Threshold: 90, Color: "#00ff00", TextColor: "#000000", LabelColor: "#33333380"},
+				{Threshold: 70, Color: "#ffff00", LabelColor: "#333"},
+				{Threshold: 0, Color: "#ff0000", TextColor: "#fff"},
+			},
+		},
+		{
+			name:        "Too many colors",
+			input:       "=#ff0000/#fff/#333/#000",
+			expected:    nil,
+			shouldError: true,
+		},
+		{
+			name:        "Invalid text color",
+			input:       "=#ff0000/blurple",
+			expected:    nil,
+			shouldError: true,
+		},
+		{
+			name:        "Missing default level",
+			input:       "90=#00ff00,70=#ffff00",
+			expected:    nil,
+			shouldError: true,
+		},
+		{
+			name:        "Duplicate level",
+			input:       "90=#00ff00,90.0=#ffff00,=#ff0000",
+			expected:    nil,
+			shouldError: true,
+		},
+		{
+			name:        "Duplicate default level",
+			input:       "0=#00ff00,=#ff0000",
+			expected:    nil,
+			shouldError: true,
+		},
+		{
+			name:        "Level above 100",
+			input:       "101=#00ff00,=#ff0000",
+			expected:    nil,
+			shouldError: true,
+		},
+		{
+			name:        "Negative level",
+			input:       "90=#00ff00,-10=#ff0000,=#ff0000",
+			expected:    nil,
+			shouldError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var levels Levels
+
+			err := levels.Set(tt.input)
+
+			if tt.shouldError {
+				if err == nil {
+					t.Errorf("Set() expected error but got none")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Set() unexpected error: %v", err)
+				return
+			}
+
+			if !slices.Equal(levels, tt.expected) {
+				t.Errorf("Set() = %v, want %v", levels, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLevelsGetColorForCoverage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		coverage float64
+		levels   Levels
+		expected string
+	}{
+		{
+			name:     "Empty levels - fallback",
+			coverage: 75.0,
+			levels:   Levels{},
+			expected: "#ff0001",
+		},
+		{
+			name:     "Single level - above threshold",
+			coverage: 85.0,
+			levels:   Levels{{Threshold: 80, Color: "#00ff00"}},
+			expected: "#00ff00",
+		},
+		{
+			name:     "Single level - below threshold",
+			coverage: 75.0,
+			levels:   Levels{{Threshold: 80, Color: "#00ff00"}},
+			expected: "#ff0001",
+		},
+		{
+			name:     "Single level - exact match",
+			coverage: 80.0,
+			levels:   Levels{{Threshold: 80, Color: "#00ff00"}},
+			expected: "#00ff00",
+		},
+		{
+			name:     "Multiple levels - high coverage",
+			coverage: 95.0,
+			levels:   Levels{{Threshold: 90, Color: "#00ff00"}, {Threshold: 70, Color: "#ffff00"}, {Threshold: 0, Color: "#ff0000"}},
+			expected: "#00ff00",
+		},
+		{
+			name:     "Multiple levels - medium coverage",
+			coverage: 75.0,
+			levels:   Levels{{Threshold: 90, Color: "#00ff00"}, {Threshold: 70, Color: "#ffff00"}, {Threshold: 0, Color: "#ff0000"}},
+			expected: "#ffff00",
+		},
+		{
+			name:     "Multiple levels - low coverage",
+			coverage: 25.0,
+			levels:   Levels{{Threshold: 90, Color: "#00ff00"}, {Threshold: 70, Color: "#ffff00"}, {Threshold: 0, Color: "#ff0000"}},
+			expected: "#ff0000",
+		},
+		{
+			name:     "Multiple levels - zero coverage",
+			coverage: 0.0,
+			levels:   Levels{{Threshold: 90, Color: "#00ff00"}, {Threshold: 70, Color: "#ffff00"}, {Threshold: 0, Color: "#ff0000"}},
+			expected: "#ff0000",
+		},
+		{
+			name:     "Multiple levels - exact boundary",
+			coverage: 70.0,
+			levels:   Levels{{Threshold: 90, Color: "#00ff00"}, {Threshold: 70, Color: "#ffff00"}, {Threshold: 0, Color: "#ff0000"}},
+			expected: "#ffff00",
+		},
+		{
+			name:     "Decimal levels",
+			coverage: 85.5,
+			levels:   Levels{{Threshold: 85, Color: "#00ff00"}, {Threshold: 70.5, Color: "#ffff00"}},
+			expected: "#00ff00",
+		},
+		{
+			name:     "Coverage below all levels but has zero level",
+			coverage: 5.0,
+			levels:   Levels{{Threshold: 90, Color: "#00ff00"}, {Threshold: 70, Color: "#ffff00"}, {Threshold: 0, Color: "#ff0000"}},
+			expected: "#ff0000",
+		},
+		{
+			name:     "Coverage below all levels without zero level",
+			coverage: 5.0,
+			levels:   Levels{{Threshold: 90, Color: "#00ff00"}, {Threshold: 70, Color: "#ffff00"}},
+			expected: "#ff0001",
+		},
+		{
+			name:     "100% coverage",
+			coverage: 100.0,
+			levels:   Levels{{Threshold: 90, Color: "#00ff00"}, {Threshold: 70, Color: "#ffff00"}, {Threshold: 0, Color: "#ff0000"}},
+			expected: "#00ff00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := tt.levels.GetColorForCoverage(tt.coverage)
+			if result != tt.expected {
+				t.Errorf("GetColorForCoverage(%f) = %q, want %q",
+					tt.coverage, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLevelsMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		levels   Levels
+		expected string
+	}{
+		{
+			name:     "Empty levels",
+			levels:   Levels{},
+			expected: `""`,
+		},
+		{
+			name:     "Single level",
+			levels:   Levels{{Threshold: 80, Color: "#00ff00"}},
+			expected: `"80=#00ff00"`,
+		},
+		{
+			name:     "Zero level",
+			levels:   Levels{{Threshold: 0, Color: "#ff0000"}},
+			expected: `"0=#ff0000"`,
+		},
+		{
+			name:     "Multiple levels",
+			levels:   Levels{{Threshold: 90, Color: "#00ff00"}, {Threshold: 0, Color: "#ff0000"}},
+			expected: `"0=#ff0000,90=#00ff00"`,
+		},
+		{
+			name:     "Decimal levels",
+			levels:   Levels{{Threshold: 85.5, Color: "#00ff00"}},
+			expected: `"85.5=#00ff00"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := json.Marshal(tt.levels)
+			if err != nil {
+				t.Errorf("MarshalJSON() unexpected error: %v", err)
+				return
+			}
+
+			// Compare string output directly
+			actual := string(data)
+			if actual != tt.expected {
+				t.Errorf("MarshalJSON() = %q, want %q", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLevelsUnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		input       string
+		expected    Levels
+		shouldError bool
+	}{
+		{
+			name:        "Empty JSON",
+			input:       `""`,
+			expected:    Levels{},
+			shouldError: false,
+		},
+		{
+			name:        "Single level",
+			input:       `"0=#00ff00"`,
+			expected:    Levels{{Threshold: 0, Color: "#00ff00"}},
+			shouldError: false,
+		},
+		{
+			name:        "Zero level",
+			input:       `"0=#ff0000"`,
+			expected:    Levels{{Threshold: 0, Color: "#ff0000"}},
+			shouldError: false,
+		},
+		{
+			name:        "Multiple levels",
+			input:       `"90=#00ff00,70=#ffff00,0=#ff0000"`,
+			expected:    Levels{{Threshold: 90, Color: "#00ff00"}, {Threshold: 70, Color: "#ffff00"}, {Threshold: 0, Color: "#ff0000"}},
+			shouldError: false,
+		},
+		{
+			name:        "Integer level keys",
+			input:       `"80=#00ff00,70=#ffff00,0=#ff0000"`,
+			expected:    Levels{{Threshold: 80, Color: "#00ff00"}, {Threshold: 70, Color: "#ffff00"}, {Threshold: 0, Color: "#ff0000"}},
+			shouldError: false,
+		},
+		{
+			name:        "Missing default level",
+			input:       `"80=#00ff00,70=#ffff00"`,
+			expected:    nil,
+			shouldError: true,
+		},
+		{
+			name:        "Invalid JSON",
+			input:       `"80=#00ff00`,
+			expected:    nil,
+			shouldError: true,
+		},
+		{
+			name:        "Invalid level key",
+			input:       `"abc=#00ff00"`,
+			expected:    nil,
+			shouldError: true,
+		},
+		{
+			name:        "Invalid hex color",
+			input:       `"80=invalid"`,
+			expected:    nil,
+			shouldError: true,
+		},
+		{
+			name:        "Invalid hex color - no hash",
+			input:       `"80=00ff00"`,
+			expected:    nil,
+			shouldError: true,
+		},
+		{
+			name:        "Invalid hex color - wrong length",
+			input:       `"80=#00ff"`,
+			expected:    nil,
+			shouldError: true,
+		},
+		{
+			name:        "Non-string JSON - number",
+			input:       `123`,
+			expected:    nil,
+			shouldError: true,
+		},
+		{
+			name:        "Non-string JSON - boolean",
+			input:       `true`,
+			expected:    nil,
+			shouldError: true,
+		},
+		{
+			name:        "Non-string JSON - array",
+			input:       `["80=#00ff00"]`,
+			expected:    nil,
+			shouldError: true,
+		},
+		{
+			name:        "Non-string JSON - object",
+			input:       `{"80": "#00ff00"}`,
+			expected:    nil,
+			shouldError: true,
+		},
+		{
+			name:        "Non-string JSON - null",
+			input:       `null`,
+			expected:    Levels{},
+			shouldError: false,
+		},
+		{
+			name:        "Malformed JSON - missing closing quote",
+			input:       `"80=#00ff00`,
+			expected:    nil,
+			shouldError: true,
+		},
+		{
+			name:        "Malformed JSON - extra comma",
+			input:       `"80=#00ff00",`,
+			expected:    nil,
+			shouldError: true,
+		},
+		{
+			name:        "Empty JSON input",
+			input:       ``,
+			expected:    nil,
+			shouldError: true,
+		},
+		{
+			name:        "Only whitespace JSON",
+			input:       `   `,
+			expected:    nil,
+			shouldError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var levels Levels
+
+			err := json.Unmarshal([]byte(tt.input), &levels)
+
+			if tt.shouldError {
+				if err == nil {
+					t.Errorf("UnmarshalJSON() expected error but got none")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Errorf("UnmarshalJSON() unexpected error: %v", err)
+				return
+			}
+
+			if !slices.Equal(levels, tt.expected) {
+				t.Errorf("UnmarshalJSON() = %v, want %v", levels, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLevelsJSONRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		levels Levels
+	}{
+		{
+			name:   "Empty levels",
+			levels: Levels{},
+		},
+		{
+			name:   "Single level",
+			levels: Levels{{Threshold: 0, Color: "#00ff00"}},
+		},
+		{
+			name:   "Multiple levels with zero",
+			levels: Levels{{Threshold: 90, Color: "#00ff00"}, {Threshold: 70, Color: "#ffff00"}, {Threshold: 0, Color: "#ff0000"}},
+		},
+		{
+			name:   "Decimal levels",
+			levels: Levels{{Threshold: 85.5, Color: "#00ff00"}, {Threshold: 72.3, Color: "#ffff00"}, {Threshold: 0, Color: "#ff0000"}},
+		},
+		{
+			name:   "3-char hex colors",
+			levels: Levels{{Threshold: 80, Color: "#0f0"}, {Threshold: 60, Color: "#ff0"}, {Threshold: 0, Color: "#f00"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Marshal to JSON
+			data, err := json.Marshal(tt.levels)
+			if err != nil {
+				t.Errorf("MarshalJSON() unexpected error: %v", err)
+				return
+			}
+
+			// Unmarshal back to Levels
+			var levels Levels
+
+			err = json.Unmarshal(data, &levels)
+			if err != nil {
+				t.Errorf("UnmarshalJSON() unexpected error: %v", err)
+				return
+			}
+
+			// Compare
+			if !slices.Equal(levels, tt.levels) {
+				t.Errorf("Round trip = %v, want %v", levels, tt.levels)
+			}
+		})
+	}
+}
+
+func TestLevelsSorted(t *testing.T) {
+	t.Parallel()
+
+	levels := Levels{{Threshold: 85.5, Color: "#00ff00"}, {Threshold: 70, Color: "#44cc11"}, {Threshold: 0, Color: "#ff0001"}}
+	expected := []Level{{Threshold: 85.5, Color: "#00ff00"}, {Threshold: 70, Color: "#44cc11"}, {Threshold: 0, Color: "#ff0001"}}
+
+	sorted := levels.Sorted()
+	if len(sorted) != len(expected) {
+		t.Fatalf("Sorted() = %v, want %v", sorted, expected)
+	}
+
+	for i := range expected {
+		if sorted[i] != expected[i] {
+			t.Errorf("Sorted()[%d] = %v, want %v", i, sorted[i], expected[i])
+		}
+	}
+
+	if sorted := (&Levels{}).Sorted(); len(sorted) != 0 {
+		t.Errorf("Sorted() of empty levels = %v, want empty", sorted)
+	}
+}
+
+func TestLevelsGetGradientColorForCoverage(t *testing.T) {
+	t.Parallel()
+
+	levels := Levels{{Threshold: 85, Color: "#44cc11"}, {Threshold: 70, Color: "#dfb317"}, {Threshold: 50, Color: "#ff8c00"}, {Threshold: 0, Color: "#ff0001"}}
+
+	tests := []struct {
+		name     string
+		coverage float64
+		levels   Levels
+		expected string
+	}{
+		{"Above the top level", 100, levels, "#44cc11"},
+		{"Exact top threshold", 85, levels, "#44cc11"},
+		{"Just below the top threshold", 84.9, levels, "#46cc11"},
+		{"Between levels", 77.5, levels, "#a3c114"},
+		{"Exact middle threshold", 70, levels, "#dfb317"},
+		{"Between lower levels", 60, levels, "#f0a00d"},
+		{"Exact default threshold", 0, levels, "#ff0001"},
+		{"Below all levels", 10, Levels{{Threshold: 50, Color: "#000"}}, "#ff0001"},
+		{"Empty levels", 50, Levels{}, "#ff0001"},
+		{"Short hex colors", 50, Levels{{Threshold: 100, Color: "#fff"}, {Threshold: 0, Color: "#000"}}, "#636363"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.levels.GetGradientColorForCoverage(tt.coverage); got != tt.expected {
+				t.Errorf("GetGradientColorForCoverage(%v) = %q, want %q", tt.coverage, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLevelsColorFor(t *testing.T) {
+	t.Parallel()
+
+	levels := Levels{{Threshold: 85, Color: "#44cc11"}, {Threshold: 70, Color: "#dfb317"}, {Threshold: 0, Color: "#ff0001"}}
+
+	tests := []struct {
+		mode     string
+		expected string
+	}{
+		{"", "#dfb317"},
+		{ColorModeDiscrete, "#dfb317"},
+		{ColorModeGradient, "#a3c114"},
+	}
+
+	for _, tt := range tests {
+		if got := levels.ColorFor(77.5, tt.mode); got != tt.expected {
+			t.Errorf("ColorFor(77.5, %q) = %q, want %q", tt.mode, got, tt.expected)
+		}
+	}
+}
+
+func TestNewLevels(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		levels   []Level
+		expected Levels
+		err      error
+	}{
+		{"Sorts levels", []Level{{Threshold: 0, Color: "#f00"}, {Threshold: 90, Color: "#0f0"}, {Threshold: 70, Color: "#ff0"}}, Levels{{Threshold: 90, Color: "#0f0"}, {Threshold: 70, Color: "#ff0"}, {Threshold: 0, Color: "#f00"}}, nil},
+		{"Default only", []Level{{Threshold: 0, Color: "#f00"}}, Levels{{Threshold: 0, Color: "#f00"}}, nil},
+		{"No levels", nil, nil, ErrMissingDefault},
+		{"Missing default", []Level{{Threshold: 90, Color: "#0f0"}, {Threshold: 70, Color: "#ff0"}}, nil, ErrMissingDefault},
+		{"Duplicate", []Level{{Threshold: 90, Color: "#0f0"}, {Threshold: 90, Color: "#ff0"}, {Threshold: 0, Color: "#f00"}}, nil, ErrDuplicateLevel},
+		{"Above 100", []Level{{Threshold: 100.5, Color: "#0f0"}, {Threshold: 0, Color: "#f00"}}, nil, ErrLevelOutOfRange},
+		{"Negative", []Level{{Threshold: -1, Color: "#0f0"}, {Threshold: 0, Color: "#f00"}}, nil, ErrLevelOutOfRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			levels, err := NewLevels(tt.levels...)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("NewLevels() error = %v, want %v", err, tt.err)
+			}
+
+			if !slices.Equal(levels, tt.expected) {
+				t.Errorf("NewLevels() = %v, want %v", levels, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLevelsValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		levels Levels
+		err    error
+	}{
+		{"Valid", Levels{{Threshold: 90, Color: "#0f0"}, {Threshold: 70, Color: "#ff0"}, {Threshold: 0, Color: "#f00"}}, nil},
+		{"Unsorted", Levels{{Threshold: 70, Color: "#ff0"}, {Threshold: 90, Color: "#0f0"}, {Threshold: 0, Color: "#f00"}}, ErrUnsortedLevels},
+		{"Missing default", Levels{{Threshold: 90, Color: "#0f0"}}, ErrMissingDefault},
+		{"Empty", Levels{}, ErrMissingDefault},
+	}
+
+	for _, tt := range tests {
+		if err := tt.levels.Validate(); !errors.Is(err, tt.err) {
+			t.Errorf("%s: Validate() = %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestLevelsColorsFor(t *testing.T) {
+	t.Parallel()
+
+	levels := Levels{
+		{Threshold: 85, Color: "#44cc11", TextColor: "#000", LabelColor: "#333"},
+		{Threshold: 70, Color: "#dfb317"},
+		{Threshold: 0, Color: "#ff0001"},
+	}
+
+	tests := []struct {
+		coverage float64
+		mode     string
+		expected Colors
+	}{
+		{90, ColorModeDiscrete, Colors{Color: "#44cc11", TextColor: "#000", LabelColor: "#333"}},
+		{75, ColorModeDiscrete, Colors{Color: "#dfb317", TextColor: "#000000", LabelColor: DefaultLabelColor}},
+		{77.5, ColorModeGradient, Colors{Color: "#a3c114", TextColor: "#000000", LabelColor: DefaultLabelColor}},
+		{10, ColorModeDiscrete, Colors{Color: "#ff0001", TextColor: "#000000", LabelColor: DefaultLabelColor}},
+	}
+
+	for _, tt := range tests {
+		if got := levels.ColorsFor(tt.coverage, tt.mode); got != tt.expected {
+			t.Errorf("ColorsFor(%v, %q) = %+v, want %+v", tt.coverage, tt.mode, got, tt.expected)
+		}
+	}
+}
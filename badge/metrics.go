@@ -0,0 +1,61 @@
+package badge
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WriteOpenMetrics writes the coverage of the module and of each of its
+// packages, the statements counts and the given timestamp in the OpenMetrics
+// text format, i.e. for the node_exporter textfile collector:
+//
+//	stampli_coverage_percent{module="example.com/app"} 85.4
+//	stampli_coverage_percent{module="example.com/app",package="example.com/app/store"} 78.6
+//
+// The module series have no package label.
+func (p *Profile) WriteOpenMetrics(w io.Writer, module string, timestamp time.Time) error {
+	bw := bufio.NewWriter(w)
+	covered, total := p.Statements()
+	packages := p.Packages()
+
+	for _, m := range []struct {
+		name, help string
+		value      func(Coverage) float64
+	}{
+		{"stampli_coverage_percent", "Statement coverage percentage.", Coverage.Percent},
+		{"stampli_coverage_statements", "Number of statements.", func(c Coverage) float64 { return float64(c.Statements) }},
+		{"stampli_coverage_covered_statements", "Number of covered statements.", func(c Coverage) float64 { return float64(c.Covered) }},
+	} {
+		bw.WriteString("# TYPE " + m.name + " gauge\n# HELP " + m.name + " " + m.help + "\n") //nolint:errcheck,gosec // see Flush
+		writeSample(bw, m.name, module, "", m.value(Coverage{Covered: covered, Statements: total}))
+
+		for _, pkg := range packages {
+			writeSample(bw, m.name, module, pkg.Name, m.value(pkg))
+		}
+	}
+
+	bw.WriteString("# TYPE stampli_coverage_timestamp_seconds gauge\n" + //nolint:errcheck,gosec // see Flush
+		"# HELP stampli_coverage_timestamp_seconds Time the coverage was measured at.\n")
+	writeSample(bw, "stampli_coverage_timestamp_seconds", module, "", float64(timestamp.UnixMilli())/1000) //nolint:mnd // ok
+
+	bw.WriteString("# EOF\n") //nolint:errcheck,gosec // see Flush
+
+	return bw.Flush() //nolint:wrapcheck // ok
+}
+
+func writeSample(w *bufio.Writer, name, module, pkg string, value float64) {
+	labels := `module="` + escapeLabel(module) + `"`
+	if pkg != "" {
+		labels += `,package="` + escapeLabel(pkg) + `"`
+	}
+
+	w.WriteString(name + "{" + labels + "} " + strconv.FormatFloat(value, 'f', -1, 64) + "\n") //nolint:errcheck,gosec // see Flush
+}
+
+// escapeLabel escapes an OpenMetrics label value.
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,47 @@
+package badge
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteOpenMetrics(t *testing.T) {
+	t.Parallel()
+
+	p, err := ParseProfile(strings.NewReader("mode: set\nm/a.go:1.1,2.2 3 1\nm/a.go:3.1,4.2 1 0\nm/b/b.go:1.1,2.2 1 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf strings.Builder
+
+	if err = p.WriteOpenMetrics(&buf, `m"`, time.UnixMilli(1700000000500)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := `# TYPE stampli_coverage_percent gauge
+# HELP stampli_coverage_percent Statement coverage percentage.
+stampli_coverage_percent{module="m\""} 60
+stampli_coverage_percent{module="m\"",package="m"} 75
+stampli_coverage_percent{module="m\"",package="m/b"} 0
+# TYPE stampli_coverage_statements gauge
+# HELP stampli_coverage_statements Number of statements.
+stampli_coverage_statements{module="m\""} 5
+stampli_coverage_statements{module="m\"",package="m"} 4
+stampli_coverage_statements{module="m\"",package="m/b"} 1
+# TYPE stampli_coverage_covered_statements gauge
+# HELP stampli_coverage_covered_statements Number of covered statements.
+stampli_coverage_covered_statements{module="m\""} 3
+stampli_coverage_covered_statements{module="m\"",package="m"} 3
+stampli_coverage_covered_statements{module="m\"",package="m/b"} 0
+# TYPE stampli_coverage_timestamp_seconds gauge
+# HELP stampli_coverage_timestamp_seconds Time the coverage was measured at.
+stampli_coverage_timestamp_seconds{module="m\""} 1700000000.5
+# EOF
+`
+
+	if got := buf.String(); got != want {
+		t.Errorf("WriteOpenMetrics() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,74 @@
+package badge
+
+import "math"
+
+// oklab is a color in the OKLab perceptual color space, see
+// https://bottosson.github.io/posts/oklab/ for the conversion formulas.
+type oklab struct {
+	L, A, B float64
+}
+
+//nolint:mnd // ok
+func hexToOklab(hexColor string) oklab {
+	r, g, b := hexToRGB(hexColor)
+	lr, lg, lb := luminanceComponent(float64(r)/255), luminanceComponent(float64(g)/255), luminanceComponent(float64(b)/255)
+
+	l := math.Cbrt(0.4122214708*lr + 0.5363325363*lg + 0.0514459929*lb)
+	m := math.Cbrt(0.2119034982*lr + 0.6806995451*lg + 0.1073969566*lb)
+	s := math.Cbrt(0.0883024619*lr + 0.2817188376*lg + 0.6299787005*lb)
+
+	return oklab{
+		L: 0.2104542553*l + 0.7936177850*m - 0.0040720468*s,
+		A: 1.9779984951*l - 2.4285922050*m + 0.4505937099*s,
+		B: 0.0259040371*l + 0.7827717662*m - 0.8086757660*s,
+	}
+}
+
+//nolint:mnd // ok
+func (c oklab) hex() string {
+	l := cube(c.L + 0.3963377774*c.A + 0.2158037573*c.B)
+	m := cube(c.L - 0.1055613458*c.A - 0.0638541728*c.B)
+	s := cube(c.L - 0.0894841775*c.A - 1.2914855480*c.B)
+
+	r := linearToSRGB(+4.0767416621*l - 3.3077115913*m + 0.2309699292*s)
+	g := linearToSRGB(-1.2684380046*l + 2.6097574011*m - 0.3413193965*s)
+	b := linearToSRGB(-0.0041960863*l - 0.7034186147*m + 1.7076147010*s)
+
+	return rgbToHex(r, g, b)
+}
+
+// lerp interpolates linearly between c and other, t being in [0, 1].
+func (c oklab) lerp(other oklab, t float64) oklab {
+	return oklab{
+		L: c.L + (other.L-c.L)*t,
+		A: c.A + (other.A-c.A)*t,
+		B: c.B + (other.B-c.B)*t,
+	}
+}
+
+// chroma returns the chroma (colorfulness) of c, 0 for grays.
+func (c oklab) chroma() float64 {
+	return math.Hypot(c.A, c.B)
+}
+
+// hue returns the hue angle of c, in radians.
+func (c oklab) hue() float64 {
+	return math.Atan2(c.B, c.A)
+}
+
+func cube(x float64) float64 {
+	return x * x * x
+}
+
+// linearToSRGB converts a linear channel value to a clamped 0..255 sRGB one.
+//
+//nolint:mnd // ok
+func linearToSRGB(c float64) int64 {
+	if c <= 0.0031308 {
+		c *= 12.92
+	} else {
+		c = 1.055*math.Pow(c, 1/2.4) - 0.055
+	}
+
+	return int64(math.Round(math.Max(0, math.Min(1, c)) * 255))
+}
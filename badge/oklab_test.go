@@ -0,0 +1,38 @@
+package badge
+
+import "testing"
+
+func TestOklabRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, color := range []string{"#000000", "#ffffff", "#44cc11", "#dfb317", "#ff8c00", "#ff0001", "#555555"} {
+		t.Run(color, func(t *testing.T) {
+			t.Parallel()
+
+			if got := hexToOklab(color).hex(); got != color {
+				t.Errorf("hexToOklab(%q).hex() = %q", color, got)
+			}
+		})
+	}
+}
+
+func TestOklabLerp(t *testing.T) {
+	t.Parallel()
+
+	black, white := hexToOklab("#000000"), hexToOklab("#ffffff")
+
+	tests := []struct {
+		t        float64
+		expected string
+	}{
+		{0, "#000000"},
+		{0.5, "#636363"}, // Perceptual middle gray, darker than the #808080 sRGB one.
+		{1, "#ffffff"},
+	}
+
+	for _, tt := range tests {
+		if got := black.lerp(white, tt.t).hex(); got != tt.expected {
+			t.Errorf("lerp(%v) = %q, want %q", tt.t, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package badge
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// pngHeight is the height of the PNG badges, as of the built-in SVG ones.
+const pngHeight = 20
+
+// RenderPNG renders a badge according to opts as a PNG image, for the places
+// that do not display SVG. It uses a built-in bitmap font and the layout of
+// the built-in templates (a label and a value box), ignoring opts.Template.
+//
+//nolint:mnd // ok
+func RenderPNG(opts Options) ([]byte, error) {
+	data, err := newData(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	face := basicfont.Face7x13
+	labelWidth := font.MeasureString(face, data.Label).Ceil() + 10
+	valueWidth := font.MeasureString(face, data.Value).Ceil() + 10
+
+	img := image.NewRGBA(image.Rect(0, 0, labelWidth+valueWidth, pngHeight))
+	draw.Draw(img, image.Rect(0, 0, labelWidth, pngHeight), image.NewUniform(rgba(data.LabelColor)), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(labelWidth, 0, labelWidth+valueWidth, pngHeight), image.NewUniform(rgba(data.Color)), image.Point{}, draw.Src)
+
+	baseline := (pngHeight-face.Height)/2 + face.Ascent
+	for _, text := range []struct {
+		s     string
+		x     int
+		color string
+	}{{data.Label, 5, LabelTextColor}, {data.Value, labelWidth + 5, data.TextColor}} {
+		d := font.Drawer{Dst: img, Src: image.NewUniform(rgba(text.color)), Face: face, Dot: fixed.P(text.x, baseline)}
+		d.DrawString(text.s)
+	}
+
+	var buf bytes.Buffer
+	if err = png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("error encoding png: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// rgba returns the given hex color as an opaque color.RGBA.
+//
+//nolint:gosec,mnd // hexToRGB components are within [0, 255]
+func rgba(hexColor string) color.RGBA {
+	r, g, b := hexToRGB(hexColor)
+	return color.RGBA{R: uint8(r), G: uint8(g), B: uint8(b), A: 255}
+}
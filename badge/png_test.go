@@ -0,0 +1,60 @@
+package badge
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestRenderPNG(t *testing.T) {
+	t.Parallel()
+
+	levels, err := NewLevels(Level{Threshold: 80, Color: "#44cc11"}, Level{Color: "#ff0001", LabelColor: "#333"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		coverage float64
+		label    color.Color
+		value    color.Color
+	}{
+		{name: "Default label color", coverage: 90, label: color.RGBA{0x55, 0x55, 0x55, 0xff}, value: color.RGBA{0x44, 0xcc, 0x11, 0xff}},
+		{name: "Level label color", coverage: 10, label: color.RGBA{0x33, 0x33, 0x33, 0xff}, value: color.RGBA{0xff, 0x00, 0x01, 0xff}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := RenderPNG(Options{Coverage: tt.coverage, Levels: levels})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			img, err := png.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("Invalid PNG: %v", err)
+			}
+
+			// "coverage" and "90.0%" are 8 and 5 7px wide characters, padded by 5px.
+			if b := img.Bounds(); b.Dx() != 8*7+10+5*7+10 || b.Dy() != 20 {
+				t.Errorf("Bounds = %v", b)
+			}
+
+			if got := img.At(1, 1); got != tt.label {
+				t.Errorf("Label color = %v, want %v", got, tt.label)
+			}
+
+			if got := img.At(img.Bounds().Dx()-2, 1); got != tt.value {
+				t.Errorf("Value color = %v, want %v", got, tt.value)
+			}
+		})
+	}
+
+	if _, err = RenderPNG(Options{Levels: levels, Type: "lines"}); err == nil {
+		t.Error("Expected an error for an unknown badge type")
+	}
+}
@@ -0,0 +1,243 @@
+package badge
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidFileFormat is returned for malformed coverage profiles.
+var ErrInvalidFileFormat = errors.New("invalid coverage file format")
+
+// Profile is a parsed Go coverage profile (the file written by -coverprofile).
+type Profile struct {
+	Mode   string
+	Blocks []ProfileBlock
+}
+
+// ProfileBlock is a single "file:start,end numStmt count" line of a profile.
+type ProfileBlock struct {
+	File      string
+	StartLine int
+	StartCol  int
+	EndLine   int
+	EndCol    int
+	NumStmt   int
+	Count     int
+}
+
+// ParseProfile reads a coverage profile, merging the duplicate blocks that
+// appear when several test binaries cover the same code (i.e. go test ./...).
+func ParseProfile(r io.Reader) (p *Profile, err error) {
+	p = &Profile{}
+	seen := map[ProfileBlock]int{} // The block index, by block without the count.
+	scanner := bufio.NewScanner(r)
+
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if p.Mode == "" {
+			mode, ok := strings.CutPrefix(line, "mode: ")
+			if !ok {
+				return nil, fmt.Errorf("%w: missing mode line", ErrInvalidFileFormat)
+			}
+
+			p.Mode = mode
+
+			continue
+		}
+
+		b, err := parseProfileBlock(line)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidFileFormat, n, err)
+		}
+
+		key := b
+		key.Count = 0
+
+		if i, ok := seen[key]; ok {
+			if p.Mode == "set" {
+				p.Blocks[i].Count = max(p.Blocks[i].Count, b.Count)
+			} else {
+				p.Blocks[i].Count += b.Count
+			}
+
+			continue
+		}
+
+		seen[key] = len(p.Blocks)
+		p.Blocks = append(p.Blocks, b)
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading coverage profile: %w", err)
+	}
+
+	if p.Mode == "" {
+		return nil, fmt.Errorf("%w: missing mode line", ErrInvalidFileFormat)
+	}
+
+	return
+}
+
+// parseProfileBlock parses a line like "pkg/file.go:15.13,17.16 2 1".
+//
+//nolint:mnd // ok
+func parseProfileBlock(line string) (b ProfileBlock, err error) {
+	fields := strings.Fields(line)
+	if len(fields) != 3 {
+		return b, fmt.Errorf("expected 3 fields, got %d", len(fields))
+	}
+
+	i := strings.LastIndexByte(fields[0], ':')
+	if i < 1 {
+		return b, fmt.Errorf("missing file name in %q", fields[0])
+	}
+
+	b.File = fields[0][:i]
+
+	_, err = fmt.Sscanf(fields[0][i+1:], "%d.%d,%d.%d", &b.StartLine, &b.StartCol, &b.EndLine, &b.EndCol)
+	if err != nil {
+		return b, fmt.Errorf("invalid position %q: %w", fields[0][i+1:], err)
+	}
+
+	if b.NumStmt, err = strconv.Atoi(fields[1]); err != nil {
+		return b, fmt.Errorf("invalid statements count: %w", err)
+	}
+
+	if b.Count, err = strconv.Atoi(fields[2]); err != nil {
+		return b, fmt.Errorf("invalid hit count: %w", err)
+	}
+
+	return
+}
+
+// Statements returns the number of covered and total statements.
+func (p *Profile) Statements() (covered, total int) {
+	for _, b := range p.Blocks {
+		total += b.NumStmt
+		if b.Count > 0 {
+			covered += b.NumStmt
+		}
+	}
+
+	return
+}
+
+// Percent returns the statement coverage percentage, 0 for an empty profile.
+func (p *Profile) Percent() float64 {
+	return percent(p.Statements())
+}
+
+// Coverage is the statement coverage of a package or of a file.
+type Coverage struct {
+	Name       string // The package import path or the file path, as in the profile.
+	Covered    int
+	Statements int
+}
+
+// Percent returns the statement coverage percentage, 0 if there are no statements.
+func (c Coverage) Percent() float64 {
+	return percent(c.Covered, c.Statements)
+}
+
+// Packages returns the statement coverage of each package, by import path.
+func (p *Profile) Packages() []Coverage {
+	return p.coverageBy(func(b ProfileBlock) string { return path.Dir(b.File) })
+}
+
+// Files returns the statement coverage of each file, by path.
+func (p *Profile) Files() []Coverage {
+	return p.coverageBy(func(b ProfileBlock) string { return b.File })
+}
+
+func (p *Profile) coverageBy(key func(ProfileBlock) string) (cov []Coverage) {
+	index := map[string]int{}
+
+	for _, b := range p.Blocks {
+		name := key(b)
+
+		i, ok := index[name]
+		if !ok {
+			i, index[name] = len(cov), len(cov)
+			cov = append(cov, Coverage{Name: name})
+		}
+
+		cov[i].Statements += b.NumStmt
+		if b.Count > 0 {
+			cov[i].Covered += b.NumStmt
+		}
+	}
+
+	slices.SortFunc(cov, func(a, b Coverage) int { return strings.Compare(a.Name, b.Name) })
+
+	return
+}
+
+type fileLineHits struct {
+	name string
+	hits map[int]int // By line number, the most of the blocks spanning it.
+}
+
+// lineHits returns the line hits of each file, sorted by file name.
+func (p *Profile) lineHits() (files []fileLineHits) {
+	index := map[string]int{}
+
+	for _, b := range p.Blocks {
+		i, ok := index[b.File]
+		if !ok {
+			i, index[b.File] = len(files), len(files)
+			files = append(files, fileLineHits{name: b.File, hits: map[int]int{}})
+		}
+
+		for line := b.StartLine; line <= b.EndLine; line++ {
+			if hits, ok := files[i].hits[line]; !ok || b.Count > hits {
+				files[i].hits[line] = b.Count
+			}
+		}
+	}
+
+	slices.SortFunc(files, func(a, b fileLineHits) int { return strings.Compare(a.name, b.name) })
+
+	return
+}
+
+func percent(covered, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return float64(covered) / float64(total) * 100 //nolint:mnd // ok
+}
+
+// ReadProfile reads and parses the given coverage profile.
+func ReadProfile(filename string) (*Profile, error) {
+	f, err := os.Open(filename) //nolint:gosec // ok
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFileFormat, err)
+	}
+
+	defer f.Close() //nolint:errcheck // read only
+
+	return ParseProfile(f)
+}
+
+// ParseCoverageFile returns the statement coverage percentage of the given
+// coverage profile.
+func ParseCoverageFile(filename string) (float64, error) {
+	p, err := ReadProfile(filename)
+	if err != nil {
+		return 0, err
+	}
+
+	return p.Percent(), nil
+}
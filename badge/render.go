@@ -0,0 +1,227 @@
+package badge
+
+import (
+	"bytes"
+	"cmp"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strconv"
+	"text/template"
+	"time"
+)
+
+// Built-in badge types.
+const (
+	TypeCoverage = "coverage"
+	TypeTests    = "tests"
+	// TypeValue badges show an arbitrary Label and Value, i.e. for rendering
+	// badges independently of the coverage profiles. They are Render only.
+	TypeValue = "value"
+)
+
+// Badge styles, of the built-in TypeValue template, see Options.Style.
+const (
+	StyleFlat       = "flat"
+	StyleFlatSquare = "flat-square"
+)
+
+// Errors returned by Render.
+var (
+	ErrUnknownType      = errors.New("unknown badge type")
+	ErrMissingTests     = errors.New("tests badge requires test results")
+	ErrUnknownColorMode = errors.New("unknown color mode")
+	ErrUnknownStyle     = errors.New("unknown badge style")
+)
+
+//go:embed coverage-badge.tmpl
+var defaultTemplate string
+
+//go:embed tests-badge.tmpl
+var defaultTestsTemplate string
+
+//go:embed value-badge.tmpl
+var defaultValueTemplate string
+
+// Options configures Render.
+type Options struct {
+	// Time is the badge generation time.
+	Time time.Time
+	// Tests holds the test results, mandatory for TypeTests badges
+	// and exposed to the template of any badge type when set.
+	Tests *TestStats
+	// Git describes the commit the badge is generated for, if any.
+	Git GitInfo
+	// Levels maps the coverage to the badge color.
+	Levels Levels
+	// DarkLevels, if set, map the coverage to the badge colors used
+	// when the viewer prefers a dark color scheme.
+	DarkLevels Levels
+	// Template is the text/template source, defaults to the built-in
+	// template of the badge Type.
+	Template string
+	// Type is the badge type, TypeCoverage if empty.
+	Type string
+	// ColorMode is the Levels color mode, ColorModeDiscrete if empty.
+	ColorMode string
+	// Coverage is the coverage percentage, or the value of TypeValue badges.
+	Coverage float64
+	// Label overrides the label of the badge type, if set.
+	Label string
+	// Unit is appended to the value of TypeValue badges, i.e. "%".
+	Unit string
+	// Style is the badge style, StyleFlat if empty.
+	Style string
+	// Covered and Statements are the covered and total statements
+	// counts, when known (i.e. from a Profile).
+	Covered    int
+	Statements int
+}
+
+// Data is the data passed to the badge templates.
+type Data struct {
+	Time       time.Time
+	Tests      *TestStats
+	Git        GitInfo
+	Levels     []Level // Sorted by descending threshold.
+	Label      string
+	Value      string
+	Style      string
+	Coverage   string
+	Color      string
+	TextColor  string
+	LabelColor string
+	Dark       *Colors // The dark color scheme variant, nil if there are no DarkLevels.
+	Width      int
+	LabelWidth int
+	ValueWidth int
+	LabelX     float64
+	ValueX     float64
+	CoveragePC float64 // The raw coverage percentage.
+	Covered    int
+	Statements int
+}
+
+// Colors are the colors of a badge.
+type Colors struct {
+	Color      string
+	TextColor  string
+	LabelColor string
+}
+
+// DefaultTemplate returns the built-in template of the given badge type.
+func DefaultTemplate(badgeType string) string {
+	switch badgeType {
+	case TypeTests:
+		return defaultTestsTemplate
+	case TypeValue:
+		return defaultValueTemplate
+	}
+
+	return defaultTemplate
+}
+
+// Render renders a badge according to opts.
+func Render(opts Options) ([]byte, error) {
+	data, err := newData(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Template == "" {
+		opts.Template = DefaultTemplate(opts.Type)
+	}
+
+	tmpl, err := template.New("badge").Funcs(funcMap(opts.Levels, opts.ColorMode)).Parse(opts.Template)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("error executing template: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func newData(opts Options) (data Data, err error) {
+	if err = opts.Levels.Validate(); err != nil {
+		return data, err
+	}
+
+	if len(opts.DarkLevels) > 0 {
+		if err = opts.DarkLevels.Validate(); err != nil {
+			return data, fmt.Errorf("invalid dark levels: %w", err)
+		}
+	}
+
+	switch opts.ColorMode {
+	case "", ColorModeDiscrete, ColorModeGradient:
+	default:
+		return data, fmt.Errorf("%w: %q", ErrUnknownColorMode, opts.ColorMode)
+	}
+
+	switch opts.Style {
+	case "", StyleFlat, StyleFlatSquare:
+		data.Style = cmp.Or(opts.Style, StyleFlat)
+	default:
+		return data, fmt.Errorf("%w: %q", ErrUnknownStyle, opts.Style)
+	}
+
+	data.Label, data.Tests = TypeCoverage, opts.Tests
+	data.Time, data.Git, data.Levels = opts.Time, opts.Git, opts.Levels.Sorted()
+	data.CoveragePC, data.Covered, data.Statements = opts.Coverage, opts.Covered, opts.Statements
+
+	// The value the levels apply to, and the color mode to use.
+	value, mode := opts.Coverage, opts.ColorMode
+
+	switch opts.Type {
+	case "", TypeCoverage:
+		data.Coverage = fmt.Sprintf("%.1f", opts.Coverage)
+		data.Value = data.Coverage + "%"
+	case TypeTests:
+		if opts.Tests == nil {
+			return data, ErrMissingTests
+		}
+
+		data.Label, data.Value = TypeTests, opts.Tests.Summary()
+		value, mode = 100, ColorModeDiscrete
+
+		if opts.Tests.Failing() {
+			value = 0
+		}
+	case TypeValue:
+		data.Label, data.Value = TypeValue, strconv.FormatFloat(opts.Coverage, 'f', -1, 64)+opts.Unit
+	default:
+		return data, fmt.Errorf("%w: %q", ErrUnknownType, opts.Type)
+	}
+
+	if opts.Label != "" {
+		data.Label = opts.Label
+	}
+
+	colors := opts.Levels.ColorsFor(value, mode)
+	data.Color, data.TextColor, data.LabelColor = colors.Color, colors.TextColor, colors.LabelColor
+
+	if len(opts.DarkLevels) > 0 {
+		dark := opts.DarkLevels.ColorsFor(value, mode)
+		data.Dark = &dark
+	}
+
+	data.layout()
+
+	return
+}
+
+// layout computes the badge dimensions from the label and value text widths,
+// using the same 5px padding on each side of a text as shields.io does.
+//
+//nolint:mnd // ok
+func (d *Data) layout() {
+	d.LabelWidth = TextWidth(d.Label) + 10
+	d.ValueWidth = TextWidth(d.Value) + 10
+	d.Width = d.LabelWidth + d.ValueWidth
+	d.LabelX = float64(d.LabelWidth) / 2
+	d.ValueX = float64(d.LabelWidth) + float64(d.ValueWidth)/2
+}
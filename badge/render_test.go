@@ -0,0 +1,207 @@
+package badge
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	levels := Levels{{Threshold: 70, Color: "#44cc11"}, {Threshold: 40, Color: "#dfb317"}, {Threshold: 0, Color: "#ff0001"}}
+
+	tests := []struct {
+		name        string
+		opts        Options
+		expectedErr error
+		contains    []string
+	}{
+		{
+			name:     "Default coverage template",
+			opts:     Options{Coverage: 85.44, Levels: levels},
+			contains: []string{"<svg", "coverage: 85.4%", "#44cc11"},
+		},
+		{
+			name:     "Explicit coverage type",
+			opts:     Options{Coverage: 50, Levels: levels, Type: TypeCoverage},
+			contains: []string{"coverage: 50.0%", "#dfb317"},
+		},
+		{
+			name: "Custom template with test stats",
+			opts: Options{
+				Coverage: 80,
+				Levels:   Levels{{Threshold: 0, Color: "#ff0001"}},
+				Template: `{{.Coverage}} {{.Tests.Passed}} {{.Tests.Summary}}`,
+				Tests:    &TestStats{Passed: 12, Skipped: 1},
+			},
+			contains: []string{"80.0 12 12 passed, 1 skipped"},
+		},
+		{
+			name: "Layout data",
+			opts: Options{
+				Coverage: 100,
+				Levels:   levels,
+				Template: `{{.Label}}={{.LabelWidth}} {{.Value}}={{.ValueWidth}} {{.Width}} {{.LabelX}} {{.ValueX}}`,
+			},
+			contains: []string{"coverage=61 100.0%=52 113 30.5 87"},
+		},
+		{
+			name: "Extended data",
+			opts: Options{
+				Coverage:   85.44,
+				Covered:    854,
+				Statements: 1000,
+				Levels:     levels,
+				Time:       time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC),
+				Git:        GitInfo{Commit: "0123456789", ShortCommit: "0123456", Branch: "main"},
+				Template: `{{.CoveragePC}} {{.Covered}}/{{.Statements}} {{.Time.Format "2006-01-02"}} ` +
+					`{{.Git.Branch}}@{{.Git.ShortCommit}}{{range .Levels}} {{.Threshold}}={{.Color}}{{end}}`,
+			},
+			contains: []string{"85.44 854/1000 2025-07-01 main@0123456 70=#44cc11 40=#dfb317 0=#ff0001"},
+		},
+		{
+			name: "JSON template",
+			opts: Options{
+				Coverage: 50,
+				Levels:   Levels{{Threshold: 0, Color: "#ff0001"}},
+				Template: `{"coverage":{{.CoveragePC}},"levels":{{toJSON .Levels}}}`,
+			},
+			contains: []string{`{"coverage":50,"levels":[{"Threshold":0,"Color":"#ff0001"}]}`},
+		},
+		{
+			name: "Gradient color mode",
+			opts: Options{
+				Coverage:  77.5,
+				Levels:    Levels{{Threshold: 85, Color: "#44cc11"}, {Threshold: 70, Color: "#dfb317"}, {Threshold: 0, Color: "#ff0001"}},
+				ColorMode: ColorModeGradient,
+				Template:  `{{.Color}} {{.TextColor}} {{levelColor 70}} {{levelColor 77.5}}`,
+			},
+			contains: []string{"#a3c114 #000000 #dfb317 #a3c114"},
+		},
+		{
+			name: "Level text and label colors",
+			opts: Options{
+				Coverage: 50,
+				Levels:   Levels{{Threshold: 40, Color: "#dfb317", TextColor: "#000", LabelColor: "#333"}, {Threshold: 0, Color: "#ff0001"}},
+			},
+			contains: []string{`class="label" fill="#333"`, `class="value" fill="#dfb317"`, `class="text" fill="#000"`},
+		},
+		{
+			name: "Dark levels",
+			opts: Options{
+				Coverage:   50,
+				Levels:     levels,
+				DarkLevels: Levels{{Threshold: 0, Color: "#2ea043", LabelColor: "#30363d"}},
+			},
+			contains: []string{
+				`class="label" fill="#555"`, `class="value" fill="#dfb317"`,
+				"@media (prefers-color-scheme: dark) { .label { fill: #30363d } .value { fill: #2ea043 } .text { fill: #000000 } }",
+			},
+		},
+		{
+			name:        "Invalid dark levels",
+			opts:        Options{Coverage: 50, Levels: levels, DarkLevels: Levels{{Threshold: 50, Color: "#2ea043"}}},
+			expectedErr: ErrMissingDefault,
+		},
+		{
+			name:        "Unknown color mode",
+			opts:        Options{Coverage: 50, Levels: levels, ColorMode: "rainbow"},
+			expectedErr: ErrUnknownColorMode,
+		},
+		{
+			name:     "Passing tests badge",
+			opts:     Options{Type: TypeTests, Levels: levels, Tests: &TestStats{Passed: 412, Skipped: 2}},
+			contains: []string{"tests: 412 passed, 2 skipped", "#44cc11"},
+		},
+		{
+			name:     "Failing tests badge",
+			opts:     Options{Type: TypeTests, Levels: levels, Tests: &TestStats{Passed: 412, Failed: 1}},
+			contains: []string{"tests: failing", "#ff0001"},
+		},
+		{
+			name:        "Tests badge without tests",
+			opts:        Options{Type: TypeTests, Levels: levels},
+			expectedErr: ErrMissingTests,
+		},
+		{
+			name:     "Value badge",
+			opts:     Options{Type: TypeValue, Label: "e2e", Coverage: 72.5, Unit: "%", Levels: levels},
+			contains: []string{"<title>e2e: 72.5%</title>", `class="value" fill="#44cc11"`, `rx="3"`, `fill="url(#s)"`},
+		},
+		{
+			name:     "Value badge escaping",
+			opts:     Options{Type: TypeValue, Label: "<a&b>", Coverage: 5, Levels: levels},
+			contains: []string{"<title>&lt;a&amp;b&gt;: 5</title>", `fill="#fff">&lt;a&amp;b&gt;</text>`},
+		},
+		{
+			name:     "Flat square style",
+			opts:     Options{Type: TypeValue, Coverage: 50, Levels: levels, Style: StyleFlatSquare},
+			contains: []string{"<title>value: 50</title>", `rx="0"`},
+		},
+		{
+			name:        "Unknown style",
+			opts:        Options{Type: TypeValue, Coverage: 50, Levels: levels, Style: "plastic"},
+			expectedErr: ErrUnknownStyle,
+		},
+		{
+			name:        "Unknown type",
+			opts:        Options{Type: "lines", Levels: levels},
+			expectedErr: ErrUnknownType,
+		},
+		{
+			name: "Template with invalid syntax",
+			opts: Options{Levels: levels, Template: `{{.Coverage}`},
+		},
+		{
+			name: "Template with undefined variable",
+			opts: Options{Levels: levels, Template: `{{.UndefinedVar}}`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := Render(tt.opts)
+			if tt.contains == nil {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+
+				if tt.expectedErr != nil && !errors.Is(err, tt.expectedErr) {
+					t.Errorf("Expected error %v, got %v", tt.expectedErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			for _, want := range tt.contains {
+				if !strings.Contains(string(result), want) {
+					t.Errorf("Result should contain %q, got: %s", want, result)
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultTemplate(t *testing.T) {
+	t.Parallel()
+
+	if DefaultTemplate("") != defaultTemplate || DefaultTemplate(TypeCoverage) != defaultTemplate {
+		t.Error("Expected the coverage template for the coverage badge type")
+	}
+
+	if DefaultTemplate(TypeTests) != defaultTestsTemplate {
+		t.Error("Expected the tests template for the tests badge type")
+	}
+
+	if DefaultTemplate(TypeValue) != defaultValueTemplate {
+		t.Error("Expected the value template for the value badge type")
+	}
+}
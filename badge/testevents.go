@@ -0,0 +1,142 @@
+package badge
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// testEvent is a single event of the go test -json (test2json) stream.
+type testEvent struct {
+	Action     string
+	Package    string
+	ImportPath string
+	Test       string
+	Elapsed    float64
+}
+
+// TestStats summarizes a go test -json event stream.
+type TestStats struct {
+	Packages map[string]*PackageStats
+	Elapsed  time.Duration
+	Passed   int
+	Failed   int
+	Skipped  int
+}
+
+// PackageStats holds the test counts of a single package.
+type PackageStats struct {
+	Status  string
+	Elapsed time.Duration
+	Passed  int
+	Failed  int
+	Skipped int
+}
+
+// ParseTestEvents reads a go test -json event stream, counting passed, failed
+// and skipped tests per package. Lines that are not JSON events (i.e. build
+// errors printed to stderr) are ignored. It returns nil if the stream contains
+// no events at all, which means the test command was not run with -json.
+func ParseTestEvents(r io.Reader) *TestStats {
+	var stats *TestStats
+
+	br := bufio.NewReader(r)
+
+	for {
+		line, err := br.ReadBytes('\n')
+		if ev, ok := decodeTestEvent(line); ok {
+			if stats == nil {
+				stats = &TestStats{Packages: map[string]*PackageStats{}}
+			}
+
+			stats.add(ev)
+		}
+
+		if err != nil {
+			break
+		}
+	}
+
+	return stats
+}
+
+func decodeTestEvent(line []byte) (ev testEvent, ok bool) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 || line[0] != '{' {
+		return
+	}
+
+	if err := json.Unmarshal(line, &ev); err != nil {
+		return
+	}
+
+	return ev, ev.Action != ""
+}
+
+func (s *TestStats) add(ev testEvent) {
+	if ev.Action == "build-fail" {
+		s.pkg(ev.ImportPath).Status = "fail"
+		return
+	}
+
+	pkg := s.pkg(ev.Package)
+
+	switch {
+	case ev.Test == "" && (ev.Action == "pass" || ev.Action == "fail" || ev.Action == "skip"):
+		elapsed := time.Duration(ev.Elapsed * float64(time.Second))
+		pkg.Status, pkg.Elapsed = ev.Action, elapsed
+		s.Elapsed += elapsed
+	case ev.Test == "":
+	case ev.Action == "pass":
+		pkg.Passed++
+		s.Passed++
+	case ev.Action == "fail":
+		pkg.Failed++
+		s.Failed++
+	case ev.Action == "skip":
+		pkg.Skipped++
+		s.Skipped++
+	}
+}
+
+func (s *TestStats) pkg(name string) *PackageStats {
+	pkg, ok := s.Packages[name]
+	if !ok {
+		pkg = &PackageStats{}
+		s.Packages[name] = pkg
+	}
+
+	return pkg
+}
+
+// Failing reports whether any test or package (i.e. due to a build failure) failed.
+func (s *TestStats) Failing() bool {
+	if s.Failed > 0 {
+		return true
+	}
+
+	for _, pkg := range s.Packages {
+		if pkg.Status == "fail" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Summary returns a short description such as "412 passed, 2 skipped" or "failing".
+func (s *TestStats) Summary() string {
+	if s.Failing() {
+		return "failing"
+	}
+
+	summary := fmt.Sprintf("%d passed", s.Passed)
+	if s.Skipped > 0 {
+		summary += fmt.Sprintf(", %d skipped", s.Skipped)
+	}
+
+	return summary
+}
@@ -0,0 +1,144 @@
+package badge
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTestEvents(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		passed   int
+		failed   int
+		skipped  int
+		packages int
+		failing  bool
+		summary  string
+		noEvents bool
+	}{
+		{
+			name:     "Passing package",
+			file:     "../testdata/test-events-pass.json",
+			passed:   3,
+			skipped:  1,
+			packages: 1,
+			summary:  "3 passed, 1 skipped",
+		},
+		{
+			name:     "Failing package",
+			file:     "../testdata/test-events-fail.json",
+			passed:   4,
+			failed:   1,
+			skipped:  1,
+			packages: 2,
+			failing:  true,
+			summary:  "failing",
+		},
+		{
+			name: "Build failure with interleaved stderr",
+			content: `# example.com/m/c
+c/c.go:3:1: syntax error: non-declaration statement outside function body
+{"Action":"build-fail","ImportPath":"example.com/m/c [example.com/m/c.test]"}
+{"Action":"pass","Package":"example.com/m/a","Test":"TestOne","Elapsed":0}
+{"Action":"pass","Package":"example.com/m/a","Elapsed":0.5}
+{not json at all}`,
+			passed:   1,
+			packages: 2,
+			failing:  true,
+			summary:  "failing",
+		},
+		{
+			name: "Only passing tests",
+			content: `{"Action":"pass","Package":"p","Test":"TestA"}
+{"Action":"pass","Package":"p","Test":"TestB"}
+{"Action":"pass","Package":"p","Elapsed":1.25}`,
+			passed:   2,
+			packages: 1,
+			summary:  "2 passed",
+		},
+		{
+			name:     "Plain go test output",
+			content:  "ok  \texample.com/m/a\t0.002s\n",
+			noEvents: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content := tt.content
+			if tt.file != "" {
+				data, err := os.ReadFile(tt.file)
+				if err != nil {
+					t.Fatalf("Failed to read %s: %v", tt.file, err)
+				}
+
+				content = string(data)
+			}
+
+			stats := ParseTestEvents(strings.NewReader(content))
+			if tt.noEvents {
+				if stats != nil {
+					t.Errorf("Expected nil stats, got %+v", stats)
+				}
+
+				return
+			}
+
+			if stats == nil {
+				t.Fatal("Expected stats, got nil")
+			}
+
+			if stats.Passed != tt.passed || stats.Failed != tt.failed || stats.Skipped != tt.skipped {
+				t.Errorf("Counts = %d/%d/%d, want %d/%d/%d", stats.Passed, stats.Failed, stats.Skipped,
+					tt.passed, tt.failed, tt.skipped)
+			}
+
+			if len(stats.Packages) != tt.packages {
+				t.Errorf("Packages = %d, want %d", len(stats.Packages), tt.packages)
+			}
+
+			if stats.Failing() != tt.failing {
+				t.Errorf("Failing() = %v, want %v", stats.Failing(), tt.failing)
+			}
+
+			if got := stats.Summary(); got != tt.summary {
+				t.Errorf("Summary() = %q, want %q", got, tt.summary)
+			}
+		})
+	}
+}
+
+func TestParseTestEventsPackages(t *testing.T) {
+	t.Parallel()
+
+	stats := ParseTestEvents(strings.NewReader(`{"Action":"pass","Package":"p","Test":"TestA"}
+{"Action":"skip","Package":"p","Test":"TestB"}
+{"Action":"pass","Package":"p","Elapsed":1.5}
+{"Action":"fail","Package":"q","Test":"TestC"}
+{"Action":"fail","Package":"q","Elapsed":0.5}`))
+
+	p, q := stats.Packages["p"], stats.Packages["q"]
+	if p == nil || q == nil {
+		t.Fatalf("Missing package stats: %+v", stats.Packages)
+	}
+
+	if p.Status != "pass" || p.Passed != 1 || p.Skipped != 1 || p.Elapsed != 1500*time.Millisecond {
+		t.Errorf("Package p = %+v", p)
+	}
+
+	if q.Status != "fail" || q.Failed != 1 || q.Elapsed != 500*time.Millisecond {
+		t.Errorf("Package q = %+v", q)
+	}
+
+	if stats.Elapsed != 2*time.Second {
+		t.Errorf("Elapsed = %v, want 2s", stats.Elapsed)
+	}
+}
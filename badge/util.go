@@ -0,0 +1,118 @@
+package badge
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// OptimalTextColor returns the one of black and white text
+// with the highest (WCAG) contrast ratio on the background color.
+func OptimalTextColor(hexColor string) string {
+	if ContrastRatio("#000000", hexColor) > ContrastRatio("#ffffff", hexColor) {
+		return "#000000"
+	}
+
+	return "#ffffff"
+}
+
+// relativeLuminance calculates the relative luminance using the formula from WCAG
+// https://www.w3.org/WAI/GL/wiki/Relative_luminance
+//
+//nolint:mnd // ok
+func relativeLuminance(hexColor string) float64 {
+	r, g, b := hexToRGB(hexColor)
+
+	rLum := luminanceComponent(float64(r) / 255.0)
+	gLum := luminanceComponent(float64(g) / 255.0)
+	bLum := luminanceComponent(float64(b) / 255.0)
+
+	return 0.2126*rLum + 0.7152*gLum + 0.0722*bLum
+}
+
+// hexToRGB parses a #rgb, #rrggbb or #rrggbbaa color into its RGB components,
+// ignoring the alpha channel.
+//
+//nolint:errcheck,mnd // hex colors are validated when set
+func hexToRGB(hexColor string) (r, g, b int64) {
+	hexColor = strings.TrimPrefix(hexColor, "#")
+
+	// Expand 3-digit hex to 6-digit.
+	if len(hexColor) == 3 {
+		hexColor = string([]byte{hexColor[0], hexColor[0], hexColor[1], hexColor[1], hexColor[2], hexColor[2]})
+	}
+
+	r, _ = strconv.ParseInt(hexColor[0:2], 16, 0)
+	g, _ = strconv.ParseInt(hexColor[2:4], 16, 0)
+	b, _ = strconv.ParseInt(hexColor[4:6], 16, 0)
+
+	return
+}
+
+// rgbToHex formats RGB components as a #rrggbb color.
+func rgbToHex(r, g, b int64) string {
+	return fmt.Sprintf("#%02x%02x%02x", r, g, b)
+}
+
+// luminanceComponent calculates the luminance component for a single RGB channel.
+//
+//nolint:mnd // ok
+func luminanceComponent(c float64) float64 {
+	if c <= 0.03928 {
+		return c / 12.92
+	}
+
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+func isValidHexColor(color string) bool {
+	if !strings.HasPrefix(color, "#") {
+		return false
+	}
+
+	color = color[1:]
+	if len(color) != 3 && len(color) != 6 && len(color) != 8 {
+		return false
+	}
+
+	for _, c := range color {
+		//nolint:staticcheck // ok
+		if !((c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// verdanaWidths holds approximate glyph widths (in px) of 11px Verdana,
+// the font used by the shields.io style badges, for the most common runes.
+var verdanaWidths = map[rune]float64{ //nolint:gochecknoglobals // ok
+	' ': 3.9, '!': 4.3, '%': 10, ',': 3.6, '-': 4.6, '.': 3.6, ':': 4.6, '/': 4.6, '(': 4.5, ')': 4.5,
+	'a': 6.7, 'b': 6.8, 'c': 5.7, 'd': 6.8, 'e': 6.6, 'f': 3.8, 'g': 6.8, 'h': 6.9, 'i': 3, 'j': 3.8,
+	'k': 6.5, 'l': 3, 'm': 10.7, 'n': 6.9, 'o': 6.7, 'p': 6.8, 'q': 6.8, 'r': 4.6, 's': 5.7, 't': 4.3,
+	'u': 6.9, 'v': 6.5, 'w': 9, 'x': 6.5, 'y': 6.5, 'z': 5.7, 'I': 4.6, 'J': 5, 'M': 9.1, 'W': 10.8,
+}
+
+// TextWidth approximates the rendered width (in px) of s in 11px Verdana.
+//
+//nolint:mnd // ok
+func TextWidth(s string) int {
+	var w float64
+
+	for _, r := range s {
+		switch cw, ok := verdanaWidths[r]; {
+		case ok:
+			w += cw
+		case r >= '0' && r <= '9':
+			w += 7
+		case r >= 'A' && r <= 'Z':
+			w += 7.5
+		default:
+			w += 7
+		}
+	}
+
+	return int(math.Ceil(w))
+}
@@ -1,4 +1,4 @@
-package main
+package badge
 
 import (
 	"math"
@@ -53,8 +53,29 @@ func TestGetOptimalTextColor(t *testing.T) {
 		{
 			name:     "Red",
 			hexColor: "#ff0001",
+			expected: "#000000",
+		},
+		{
+			name:     "Green",
+			hexColor: "#44cc11",
+			expected: "#000000",
+		},
+		{
+			name:     "Dark red",
+			hexColor: "#b00020",
+			expected: "#ffffff",
+		},
+		// 8-digit hex colors ignore the alpha channel
+		{
+			name:     "Translucent black",
+			hexColor: "#00000080",
 			expected: "#ffffff",
 		},
+		{
+			name:     "Translucent white",
+			hexColor: "#ffffff00",
+			expected: "#000000",
+		},
 		// 3-digit hex colors
 		{
 			name:     "White 3-digit",
@@ -69,7 +90,7 @@ func TestGetOptimalTextColor(t *testing.T) {
 		{
 			name:     "Red 3-digit",
 			hexColor: "#f00",
-			expected: "#ffffff",
+			expected: "#000000",
 		},
 		{
 			name:     "Light green 3-digit",
@@ -91,12 +112,12 @@ func TestGetOptimalTextColor(t *testing.T) {
 		{
 			name:     "Medium gray",
 			hexColor: "#808080",
-			expected: "#ffffff",
+			expected: "#000000",
 		},
 		{
 			name:     "Light medium gray",
 			hexColor: "#a0a0a0",
-			expected: "#ffffff",
+			expected: "#000000",
 		},
 	}
 
@@ -104,9 +125,9 @@ func TestGetOptimalTextColor(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			result := getOptimalTextColor(tt.hexColor)
+			result := OptimalTextColor(tt.hexColor)
 			if result != tt.expected {
-				t.Errorf("getOptimalTextColor(%q) = %q, want %q",
+				t.Errorf("OptimalTextColor(%q) = %q, want %q",
 					tt.hexColor, result, tt.expected)
 			}
 		})
@@ -230,6 +251,11 @@ func TestIsValidHexColor(t *testing.T) {
 			color:    "f00",
 			expected: false,
 		},
+		{
+			name:     "Valid 8-digit with alpha",
+			color:    "#44cc1180",
+			expected: true,
+		},
 		// Invalid - wrong length
 		{
 			name:     "Too short",
@@ -322,3 +348,28 @@ func TestIsValidHexColor(t *testing.T) {
 		})
 	}
 }
+
+func TestTextWidth(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		text     string
+		expected int
+	}{
+		{"", 0},
+		{"coverage", 51},
+		{"tests", 27},
+		{"100%", 31},
+		{"ÄÖ", 14},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			t.Parallel()
+
+			if got := TextWidth(tt.text); got != tt.expected {
+				t.Errorf("TextWidth(%q) = %d, want %d", tt.text, got, tt.expected)
+			}
+		})
+	}
+}
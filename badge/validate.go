@@ -0,0 +1,213 @@
+package badge
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Errors returned when validating a Config.
+var (
+	ErrUnknownKey         = errors.New("unknown config key")
+	ErrEmptyOutput        = errors.New("empty output file")
+	ErrInvalidOutput      = errors.New("invalid output file")
+	ErrInvalidMarkdown    = errors.New("invalid markdown file")
+	ErrInvalidCobertura   = errors.New("invalid cobertura file")
+	ErrInvalidHTMLDir     = errors.New("invalid html directory")
+	ErrInvalidMetrics     = errors.New("invalid metrics file")
+	ErrUnreadableTemplate = errors.New("unreadable template file")
+	ErrInvalidMinCoverage = errors.New("min coverage out of the [0, 100] range")
+)
+
+// unknownFieldPrefix prefixes the (untyped) encoding/json errors
+// of DisallowUnknownFields.
+const unknownFieldPrefix = "json: unknown field "
+
+// yamlLineRe matches the yaml.v3 syntax errors, which only carry a line.
+var yamlLineRe = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+//go:embed stampli.schema.json
+var schema string
+
+// Schema returns the JSON Schema of the config file, for editors to validate
+// and autocomplete it (reference it via the "$schema" key).
+func Schema() string {
+	return schema
+}
+
+// ConfigError is an error of a config file, with the (1-based) position
+// it occurred at, if known.
+type ConfigError struct {
+	File   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *ConfigError) Error() string {
+	switch {
+	case e.Line > 0 && e.Column > 0:
+		return fmt.Sprintf("%s:%d:%d: %v", e.File, e.Line, e.Column, e.Err)
+	case e.Line > 0:
+		return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+	default:
+		return fmt.Sprintf("%s: %v", e.File, e.Err)
+	}
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// newConfigError wraps the decoding error err of the given config file,
+// locating it in its raw content where possible.
+func newConfigError(filename string, raw []byte, err error) error {
+	e := &ConfigError{File: filename, Err: err}
+
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		tomlErr   toml.ParseError
+	)
+
+	switch {
+	case errors.As(err, &syntaxErr) && FormatOf(filename) == FormatJSON:
+		e.Line, e.Column = offsetPosition(raw, int(syntaxErr.Offset)-1) // Offset is past the invalid byte.
+	case errors.As(err, &typeErr):
+		path := strings.Split(typeErr.Field, ".")
+		e.Err = fmt.Errorf("%s: cannot use %s as %s", typeErr.Field, typeErr.Value, typeErr.Type)
+		e.Line, e.Column = keyPosition(raw, path[len(path)-1])
+	case errors.As(err, &tomlErr):
+		e.Line, e.Column, e.Err = tomlErr.Position.Line, tomlErr.Position.Col, errors.New(tomlErr.Message) //nolint:err113 // ok
+	case strings.HasPrefix(err.Error(), unknownFieldPrefix):
+		key, _ := strconv.Unquote(strings.TrimPrefix(err.Error(), unknownFieldPrefix))
+		e.Err = fmt.Errorf("%w %q", ErrUnknownKey, key)
+		e.Line, e.Column = keyPosition(raw, key)
+	default:
+		if m := yamlLineRe.FindStringSubmatch(err.Error()); m != nil {
+			e.Line, _ = strconv.Atoi(m[1])
+			e.Err = errors.New(m[2]) //nolint:err113 // ok
+		}
+	}
+
+	return e
+}
+
+// offsetPosition returns the line and column of the given byte offset.
+func offsetPosition(raw []byte, offset int) (line, column int) {
+	before := raw[:min(max(offset, 0), len(raw))]
+	return bytes.Count(before, []byte("\n")) + 1, len(before) - bytes.LastIndexByte(before, '\n')
+}
+
+// keyPosition returns the line and column of the first definition of key,
+// in any of the config formats, or zeros if not found.
+func keyPosition(raw []byte, key string) (line, column int) {
+	re := regexp.MustCompile(`(?m)(?:^|[{,])[ \t]*(?:-[ \t]+)?(["']?` + regexp.QuoteMeta(key) + `["']?)[ \t]*[:=]`)
+	if m := re.FindSubmatchIndex(raw); m != nil {
+		return offsetPosition(raw, m[2])
+	}
+
+	return
+}
+
+// Validate checks the constraints of c that decoding it cannot: a non-empty
+// output file in a writable directory, a readable template file (so it must
+// be called before LoadTemplate), valid levels, known badge type and color
+// mode and a min coverage within the [0, 100] range. It reports all the
+// problems found, joined.
+func (c *Config) Validate() error {
+	errs := []error{}
+
+	if c.OutputFile == "" {
+		errs = append(errs, ErrEmptyOutput)
+	} else if err := checkWritableDir(filepath.Dir(c.OutputFile)); err != nil {
+		errs = append(errs, fmt.Errorf("%w %s: %w", ErrInvalidOutput, c.OutputFile, err))
+	}
+
+	for _, report := range []struct {
+		file string
+		err  error
+	}{{c.MarkdownFile, ErrInvalidMarkdown}, {c.CoberturaFile, ErrInvalidCobertura}, {c.MetricsFile, ErrInvalidMetrics}} {
+		if report.file == "" {
+			continue
+		}
+
+		if err := checkWritableDir(filepath.Dir(report.file)); err != nil {
+			errs = append(errs, fmt.Errorf("%w %s: %w", report.err, report.file, err))
+		}
+	}
+
+	if c.HTMLDir != "" {
+		dir := c.HTMLDir
+		if _, err := os.Stat(dir); err != nil {
+			dir = filepath.Dir(dir) // Created when writing the report.
+		}
+
+		if err := checkWritableDir(dir); err != nil {
+			errs = append(errs, fmt.Errorf("%w %s: %w", ErrInvalidHTMLDir, c.HTMLDir, err))
+		}
+	}
+
+	if c.Template != "" {
+		if f, err := os.Open(c.Template); err != nil {
+			errs = append(errs, fmt.Errorf("%w: %w", ErrUnreadableTemplate, err))
+		} else {
+			f.Close() //nolint:errcheck,gosec // ok
+		}
+	}
+
+	if err := c.Levels.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid levels: %w", err))
+	}
+
+	if len(c.DarkLevels) > 0 {
+		if err := c.DarkLevels.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("invalid dark levels: %w", err))
+		}
+	}
+
+	switch c.BadgeType {
+	case "", TypeCoverage, TypeTests:
+	default:
+		errs = append(errs, fmt.Errorf("%w: %q", ErrUnknownType, c.BadgeType))
+	}
+
+	switch c.ColorMode {
+	case "", ColorModeDiscrete, ColorModeGradient:
+	default:
+		errs = append(errs, fmt.Errorf("%w: %q", ErrUnknownColorMode, c.ColorMode))
+	}
+
+	for _, minCoverage := range []float64{c.MinCoverage, c.FileMinCoverage} {
+		if minCoverage < 0 || minCoverage > 100 {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrInvalidMinCoverage, formatThreshold(minCoverage)))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkWritableDir checks that files can be created in dir.
+func checkWritableDir(dir string) error {
+	f, err := os.CreateTemp(dir, ".stampli-*")
+	if err != nil {
+		var pathErr *os.PathError
+		if errors.As(err, &pathErr) {
+			return fmt.Errorf("directory %s: %w", dir, pathErr.Err)
+		}
+
+		return err //nolint:wrapcheck // ok
+	}
+
+	f.Close()                  //nolint:errcheck,gosec // ok
+	return os.Remove(f.Name()) //nolint:wrapcheck // ok
+}
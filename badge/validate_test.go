@@ -0,0 +1,246 @@
+package badge
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoadFileErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		expected string
+		wantErr  error
+	}{
+		{
+			name:     "Unknown JSON key",
+			filename: "stampli.json",
+			content:  "{\n  \"levels\": \"=#f00\",\n  \"outpuFile\": \"badge.svg\"\n}\n",
+			expected: `stampli.json:3:3: unknown config key "outpuFile"`,
+			wantErr:  ErrUnknownKey,
+		},
+		{
+			name:     "Unknown YAML key",
+			filename: "stampli.yaml",
+			content:  "quiet: true\noutpuFile: badge.svg\n",
+			expected: `stampli.yaml:2:1: unknown config key "outpuFile"`,
+			wantErr:  ErrUnknownKey,
+		},
+		{
+			name:     "Unknown TOML key",
+			filename: "stampli.toml",
+			content:  "quiet = true\n  outpuFile = \"badge.svg\"\n",
+			expected: `stampli.toml:2:3: unknown config key "outpuFile"`,
+			wantErr:  ErrUnknownKey,
+		},
+		{
+			name:     "Unknown level key",
+			filename: "stampli.yml",
+			content:  "levels:\n  - treshold: 90\n    color: green\n",
+			expected: `stampli.yml:2:5: unknown config key "treshold"`,
+			wantErr:  ErrUnknownKey,
+		},
+		{
+			name:     "JSON type error",
+			filename: "stampli.json",
+			content:  "{\n  \"quiet\": \"yes\"\n}\n",
+			expected: "stampli.json:2:3: quiet: cannot use string as bool",
+		},
+		{
+			name:     "YAML type error",
+			filename: "stampli.yaml",
+			content:  "quiet: true\nminCoverage: high\n",
+			expected: "stampli.yaml:2:1: minCoverage: cannot use string as float64",
+		},
+		{
+			name:     "JSON syntax error",
+			filename: "stampli.json",
+			content:  "{\n  \"quiet\": true,\n}\n",
+			expected: "stampli.json:3:1: invalid character '}' looking for beginning of object key string",
+		},
+		{
+			name:     "YAML syntax error",
+			filename: "stampli.yaml",
+			content:  "quiet: true\noutputFile: badge.svg\n  levels: =red\n",
+			expected: "stampli.yaml:3: mapping values are not allowed in this context",
+		},
+		{
+			name:     "TOML syntax error",
+			filename: "stampli.toml",
+			content:  "quiet = true\noutputFile = badge.svg\n",
+			expected: "stampli.toml:2:14: expected value but found \"badge\" instead",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			filename := filepath.Join(dir, tt.filename)
+
+			if err := os.WriteFile(filename, []byte(tt.content), 0o644); err != nil {
+				t.Fatalf("Failed to create config file: %v", err)
+			}
+
+			err := (&Config{}).LoadFile(filename)
+
+			var configErr *ConfigError
+			if !errors.As(err, &configErr) {
+				t.Fatalf("Expected a *ConfigError, got %v", err)
+			}
+
+			if got := strings.TrimPrefix(err.Error(), dir+string(filepath.Separator)); got != tt.expected {
+				t.Errorf("Error = %q, want %q", got, tt.expected)
+			}
+
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	template := filepath.Join(dir, "badge.tmpl")
+
+	if err := os.WriteFile(template, []byte("{{.Value}}"), 0o644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	valid := func() Config {
+		return Config{
+			OutputFile: filepath.Join(dir, "badge.svg"),
+			Template:   template,
+			Levels:     Levels{{Threshold: 70, Color: "#44cc11"}, {Threshold: 0, Color: "#ff0001"}},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr []error
+	}{
+		{name: "Valid config", modify: func(*Config) {}},
+		{name: "Empty output", modify: func(c *Config) { c.OutputFile = "" }, wantErr: []error{ErrEmptyOutput}},
+		{
+			name:    "Missing output directory",
+			modify:  func(c *Config) { c.OutputFile = filepath.Join(dir, "missing", "badge.svg") },
+			wantErr: []error{ErrInvalidOutput, os.ErrNotExist},
+		},
+		{
+			name:    "Missing markdown directory",
+			modify:  func(c *Config) { c.MarkdownFile = filepath.Join(dir, "missing", "coverage.md") },
+			wantErr: []error{ErrInvalidMarkdown, os.ErrNotExist},
+		},
+		{
+			name:    "Missing cobertura directory",
+			modify:  func(c *Config) { c.CoberturaFile = filepath.Join(dir, "missing", "coverage.xml") },
+			wantErr: []error{ErrInvalidCobertura, os.ErrNotExist},
+		},
+		{
+			name:    "Missing html parent directory",
+			modify:  func(c *Config) { c.HTMLDir = filepath.Join(dir, "missing", "html") },
+			wantErr: []error{ErrInvalidHTMLDir, os.ErrNotExist},
+		},
+		{
+			name:    "Missing metrics directory",
+			modify:  func(c *Config) { c.MetricsFile = filepath.Join(dir, "missing", "stampli.prom") },
+			wantErr: []error{ErrInvalidMetrics, os.ErrNotExist},
+		},
+		{
+			name:    "Missing template",
+			modify:  func(c *Config) { c.Template = filepath.Join(dir, "missing.tmpl") },
+			wantErr: []error{ErrUnreadableTemplate, os.ErrNotExist},
+		},
+		{name: "Missing levels", modify: func(c *Config) { c.Levels = nil }, wantErr: []error{ErrMissingDefault}},
+		{
+			name:    "Invalid dark levels",
+			modify:  func(c *Config) { c.DarkLevels = Levels{{Threshold: 50, Color: "#2ea043"}} },
+			wantErr: []error{ErrMissingDefault},
+		},
+		{
+			name: "All problems reported",
+			modify: func(c *Config) {
+				c.BadgeType, c.ColorMode, c.MinCoverage = "lines", "rainbow", 101
+			},
+			wantErr: []error{ErrUnknownType, ErrUnknownColorMode, ErrInvalidMinCoverage},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := valid()
+			tt.modify(&cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			for _, want := range tt.wantErr {
+				if !errors.Is(err, want) {
+					t.Errorf("Expected error %v, got %v", want, err)
+				}
+			}
+		})
+	}
+
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) != 1 {
+		t.Errorf("Validate should not leave files behind, got %v (%v)", entries, err)
+	}
+}
+
+func TestSchema(t *testing.T) {
+	t.Parallel()
+
+	var schema struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+		Defs       struct {
+			Config struct {
+				Properties map[string]json.RawMessage `json:"properties"`
+			} `json:"config"`
+		} `json:"$defs"`
+	}
+
+	if err := json.Unmarshal([]byte(Schema()), &schema); err != nil {
+		t.Fatalf("Invalid schema: %v", err)
+	}
+
+	// Every (JSON) config key must be in the schema, and only those.
+	js, err := json.Marshal(Config{
+		Schema: "x", Levels: Levels{{}}, DarkLevels: Levels{{}},
+		MinCoverage: 1, FileMinCoverage: 1, BadgeType: "x", ColorMode: "x", CoverageFile: "x",
+		MarkdownFile: "x", BaselineFile: "x", GitLab: true, CoberturaFile: "x", HTMLDir: "x", MetricsFile: "x",
+		Profiles: map[string]*ConfigProfile{"x": {}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var keys map[string]json.RawMessage
+	if err = json.Unmarshal(js, &keys); err != nil {
+		t.Fatal(err)
+	}
+
+	maps.Copy(schema.Properties, schema.Defs.Config.Properties)
+
+	got, want := slices.Sorted(maps.Keys(schema.Properties)), slices.Sorted(maps.Keys(keys))
+	if !slices.Equal(got, want) {
+		t.Errorf("Schema properties = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// command is a stampli subcommand, accepting the flags of the given groups.
+type command struct {
+	name    string
+	args    string // The positional arguments, for the usage.
+	summary string
+	flags   flagGroup
+	run     func(a *app) error
+}
+
+// flagGroup is a bitmask of the flags groups a command accepts.
+type flagGroup int
+
+// Flags groups, -quiet is accepted by all the commands.
+const (
+	configFlags       flagGroup = 1 << iota // -config, -profile, -all-profiles (and the config files loading).
+	commandFlags                            // -command, -auto-clean.
+	coverageFileFlags                       // -coverage-file.
+	coverageFlags                           // -coverage.
+	badgeFlags                              // -badge-type, -color-mode, -output, -template, -levels, -dark-levels.
+	minFlags                                // -min, -file-min.
+	reportFlags                             // -markdown, -baseline, -html, -metrics.
+	gitlabFlags                             // -gitlab, -cobertura.
+	dumpFlags                               // -dump-template, -dump-config, -dump-schema.
+	initFlags                               // -force, -with-*.
+	serveFlags                              // -addr.
+	renderFlags                             // -label, -value, -unit, -style, -format.
+	watchFlags                              // -watch, -watch-interval.
+)
+
+var errUnknownCommand = errors.New("unknown command")
+
+// commands are the stampli commands, the first one being the default.
+var commands = []*command{ //nolint:gochecknoglobals // ok
+	{
+		name:    "run",
+		summary: "Run the tests and generate the badge (the default command).",
+		flags:   configFlags | commandFlags | coverageFileFlags | coverageFlags | badgeFlags | minFlags | reportFlags | gitlabFlags | watchFlags | dumpFlags,
+		run:     (*app).generate,
+	},
+	{
+		name:    "badge",
+		summary: "Generate the badge from the existing coverage profile (or -coverage), without running the tests.",
+		flags:   configFlags | coverageFileFlags | coverageFlags | badgeFlags | minFlags | reportFlags | gitlabFlags | watchFlags,
+		run:     (*app).renderBadge,
+	},
+	{
+		name:    "check",
+		summary: "Run the tests and fail if the coverage is below -min, without generating the badge.",
+		flags:   configFlags | commandFlags | coverageFileFlags | coverageFlags | minFlags,
+		run:     (*app).check,
+	},
+	{
+		name: "init",
+		args: "[file]",
+		summary: "Write the default configuration to file (stampli.json by default, YAML or TOML by extension) and, optionally, " +
+			"the default template, a Makefile target, a git pre-commit hook and a GitHub Actions workflow.",
+		flags: initFlags,
+		run:   (*app).initConfig,
+	},
+	{
+		name:    "validate",
+		summary: "Validate the configuration, including the contrast of the levels colors.",
+		flags:   configFlags | badgeFlags | minFlags,
+		run:     (*app).validate,
+	},
+	{
+		name:    "report",
+		summary: "Run the tests and print the coverage of each package (and write the -markdown, -html, -metrics and -cobertura reports, if set).",
+		flags:   configFlags | commandFlags | coverageFileFlags | reportFlags | gitlabFlags,
+		run:     (*app).report,
+	},
+	{
+		name: "serve",
+		summary: "Serve the coverage badge as /badge.svg, /badge.json (shields.io endpoint) and /badge.png, " +
+			"rendered again whenever the coverage profile or the template change, and value badges as /badge?label=...&value=....",
+		flags: configFlags | coverageFileFlags | coverageFlags | badgeFlags | serveFlags,
+		run:   (*app).serve,
+	},
+	{
+		name:    "render",
+		summary: "Render a badge of any -label and -value (i.e. not a coverage one) to stdout, or to -output if given.",
+		flags:   configFlags | badgeFlags | renderFlags,
+		run:     (*app).render,
+	},
+}
+
+// parseCommand returns the command given as the first argument, if any (else
+// the default one, for backward compatibility), and the remaining arguments.
+// The help command prints the usage of the command it is given (as -help).
+func parseCommand(fs *flag.FlagSet, args []string) (*command, []string, error) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return commands[0], args, nil
+	}
+
+	name, args := args[0], args[1:]
+
+	help := name == "help"
+	if help {
+		if len(args) == 0 {
+			commands[0].usage(fs)
+			return nil, nil, flag.ErrHelp
+		}
+
+		name = args[0]
+	}
+
+	i := slices.IndexFunc(commands, func(c *command) bool { return c.name == name })
+	if i < 0 {
+		return nil, nil, fmt.Errorf("%w: %q (see stampli help)", errUnknownCommand, name)
+	}
+
+	if help {
+		// Let the flags parsing print the usage, once the command flags are registered.
+		return commands[i], []string{"-help"}, nil
+	}
+
+	return commands[i], args, nil
+}
+
+// usage prints the usage of the command, listing all the commands
+// for the default one.
+func (c *command) usage(fs *flag.FlagSet) {
+	w := fs.Output()
+
+	if c == commands[0] {
+		fmt.Fprintf(w, "Usage: stampli [command] [flags]\n\nCommands:\n") //nolint:errcheck // ok
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0) //nolint:mnd // ok
+		for _, cmd := range commands {
+			fmt.Fprintf(tw, "  %s\t%s\n", cmd.name, cmd.summary) //nolint:errcheck // ok
+		}
+
+		tw.Flush()                                                                                           //nolint:errcheck,gosec // ok
+		fmt.Fprintf(w, "\nUse \"stampli help <command>\" for the flags of each command.\n\nFlags of run:\n") //nolint:errcheck // ok
+	} else {
+		fmt.Fprintf(w, "Usage: stampli %s [flags] %s\n\n%s\n\nFlags:\n", c.name, c.args, c.summary) //nolint:errcheck // ok
+	}
+
+	fs.PrintDefaults()
+}
+
+// renderBadge generates the badge from the existing coverage profile.
+func (a *app) renderBadge() error {
+	a.skipTests = true
+	return a.generate()
+}
+
+// check fails if the coverage is below the minimum.
+func (a *app) check() error {
+	if err := a.loadCoverage(); err != nil {
+		return err
+	}
+
+	if !a.Quiet {
+		fmt.Fprintf(a.dumpSink, "Coverage: %.1f%% (minimum %.1f%%)\n", *a.CoveragePC, a.MinCoverage) //nolint:errcheck // ok
+	}
+
+	if err := a.githubActionsCoverage(); err != nil {
+		return err
+	}
+
+	return a.checkMin()
+}
+
+// report prints the coverage of each package, and the total one.
+func (a *app) report() error {
+	coverage, err := a.runTestsAndGetCoverage()
+	if err != nil {
+		return fmt.Errorf("error getting coverage: %w", err)
+	}
+
+	a.CoveragePC = &coverage
+	w := tabwriter.NewWriter(a.dumpSink, 0, 0, 2, ' ', 0) //nolint:mnd // ok
+
+	fmt.Fprintf(w, "Package\tStatements\tCoverage\n") //nolint:errcheck // ok
+
+	for _, pkg := range a.profile.Packages() {
+		fmt.Fprintf(w, "%s\t%d/%d\t%.1f%%\n", pkg.Name, pkg.Covered, pkg.Statements, pkg.Percent()) //nolint:errcheck // ok
+	}
+
+	covered, total := a.profile.Statements()
+	fmt.Fprintf(w, "Total\t%d/%d\t%.1f%%\n", covered, total, a.profile.Percent()) //nolint:errcheck // ok
+
+	if err = w.Flush(); err != nil {
+		return err //nolint:wrapcheck // ok
+	}
+
+	if err = a.githubActionsCoverage(); err != nil {
+		return err
+	}
+
+	if err = a.writeMarkdown(); err != nil {
+		return err
+	}
+
+	if a.HTMLDir != "" {
+		if err = a.writeHTMLReport(""); err != nil {
+			return err
+		}
+	}
+
+	if err = a.writeMetrics(); err != nil {
+		return err
+	}
+
+	return a.gitlab()
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseCommand(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string // The command name, or the usage output for help.
+		wantArgs []string
+		wantErr  error
+	}{
+		{name: "No arguments", expected: "run"},
+		{name: "Flags only", args: []string{"-quiet", "-min", "80"}, expected: "run", wantArgs: []string{"-quiet", "-min", "80"}},
+		{name: "Explicit command", args: []string{"check", "-min", "80"}, expected: "check", wantArgs: []string{"-min", "80"}},
+		{name: "Help", args: []string{"help"}, expected: "Usage: stampli [command] [flags]", wantErr: flag.ErrHelp},
+		{name: "Command help", args: []string{"help", "init"}, expected: "init", wantArgs: []string{"-help"}},
+		{name: "Unknown command", args: []string{"lint"}, wantErr: errUnknownCommand},
+		{name: "Unknown command help", args: []string{"help", "lint"}, wantErr: errUnknownCommand},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var output strings.Builder
+
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(&output)
+
+			cmd, args, err := parseCommand(fs, tt.args)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+
+			switch {
+			case errors.Is(err, flag.ErrHelp):
+				if !strings.Contains(output.String(), tt.expected) {
+					t.Errorf("Usage should contain %q, got:\n%s", tt.expected, output.String())
+				}
+			case err == nil:
+				if cmd.name != tt.expected || strings.Join(args, " ") != strings.Join(tt.wantArgs, " ") {
+					t.Errorf("parseCommand() = %q, %q, want %q, %q", cmd.name, args, tt.expected, tt.wantArgs)
+				}
+			}
+		})
+	}
+}
+
+func TestCommandHelp(t *testing.T) {
+	t.Parallel()
+
+	var output strings.Builder
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(&output)
+
+	if _, err := newApp(fs, []string{"help", "badge"}); !errors.Is(err, flag.ErrHelp) {
+		t.Fatalf("Expected error %v, got %v", flag.ErrHelp, err)
+	}
+
+	for _, want := range []string{"Usage: stampli badge [flags]", "-coverage-file", "-markdown"} {
+		if !strings.Contains(output.String(), want) {
+			t.Errorf("Usage should contain %q, got:\n%s", want, output.String())
+		}
+	}
+}
+
+func TestCommandFlags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		args        []string
+		expectError bool
+	}{
+		{args: []string{"-dump-config"}},
+		{args: []string{"check", "-min", "80", "-command", "make test"}},
+		{args: []string{"check", "-output", "badge.svg"}, expectError: true},
+		{args: []string{"badge", "-coverage", "80", "-levels", "=red"}},
+		{args: []string{"badge", "-command", "make test"}, expectError: true},
+		{args: []string{"badge", "-watch", "-watch-interval", "2s"}},
+		{args: []string{"check", "-watch"}, expectError: true},
+		{args: []string{"report", "-coverage", "80"}, expectError: true},
+		{args: []string{"init", "stampli.yaml"}},
+		{args: []string{"init", "-config", "stampli.yaml"}, expectError: true},
+		{args: []string{"init", "stampli.yaml", "stampli.toml"}, expectError: true},
+		{args: []string{"-dump-config", "yaml"}, expectError: true},
+		{args: []string{"-dump-format", "yaml"}},
+		{args: []string{"-dump-format", "xml"}, expectError: true},
+		{args: []string{"badge", "coverage.out"}, expectError: true},
+		{args: []string{"check", "./..."}, expectError: true},
+		{args: []string{"report", "x"}, expectError: true},
+		{args: []string{"serve", "x"}, expectError: true},
+		{args: []string{"render", "-value", "1", "x"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			t.Parallel()
+
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(&strings.Builder{})
+
+			if _, err := newApp(fs, tt.args); (err != nil) != tt.expectError {
+				t.Errorf("newApp() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestBadgeCommand(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	coverageFile := copyCoverageSample(t, dir)
+	output := filepath.Join(dir, "badge.svg")
+
+	a, err := newApp(flag.NewFlagSet("test", flag.ContinueOnError),
+		[]string{"badge", "-quiet", "-coverage-file", coverageFile, "-output", output})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	a.TestCommand = "false" // Must not run.
+
+	if err = a.run(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if svg, err := os.ReadFile(output); err != nil || !strings.Contains(string(svg), "93.1%") {
+		t.Errorf("Badge should contain the profile coverage, got %s (%v)", svg, err)
+	}
+
+	// The existing profile is not ours to clean up.
+	if _, err = os.Stat(coverageFile); err != nil {
+		t.Errorf("Coverage file should be kept: %v", err)
+	}
+
+	a.BadgeType = "tests"
+	if err = a.run(); !errors.Is(err, errTestsNotRun) {
+		t.Errorf("Expected error %v, got %v", errTestsNotRun, err)
+	}
+}
+
+func TestCheckCommand(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		min      string
+		expected string
+		wantErr  error
+	}{
+		{"70", "Coverage: 75.0% (minimum 70.0%)\n::notice title=Coverage::75.0% of statements\n", nil},
+		{"80", "Coverage: 75.0% (minimum 80.0%)\n::notice title=Coverage::75.0% of statements\n", errBelowMinCoverage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.min, func(t *testing.T) {
+			t.Parallel()
+
+			a, err := newApp(flag.NewFlagSet("test", flag.ContinueOnError), []string{"check", "-coverage", "75", "-min", tt.min})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var output strings.Builder
+
+			outputFile := filepath.Join(t.TempDir(), "output")
+			env := map[string]string{"GITHUB_ACTIONS": "true", "GITHUB_OUTPUT": outputFile}
+			a.dumpSink, a.getenv = &output, func(k string) string { return env[k] }
+
+			if err = a.run(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+
+			if output.String() != tt.expected {
+				t.Errorf("Output = %q, want %q", output.String(), tt.expected)
+			}
+
+			if got, err := os.ReadFile(outputFile); err != nil || !strings.HasPrefix(string(got), "coverage=75.0\n") {
+				t.Errorf("GitHub outputs = %q (%v), want the coverage", got, err)
+			}
+		})
+	}
+}
+
+func TestReportCommand(t *testing.T) {
+	t.Parallel()
+
+	coverageFile := copyCoverageSample(t, t.TempDir())
+
+	a, err := newApp(flag.NewFlagSet("test", flag.ContinueOnError),
+		[]string{"report", "-command", "true", "-coverage-file", coverageFile})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var output strings.Builder
+
+	summaryFile := filepath.Join(t.TempDir(), "summary.md")
+	env := map[string]string{"GITHUB_ACTIONS": "true", "GITHUB_STEP_SUMMARY": summaryFile}
+	a.dumpSink, a.getenv = &output, func(k string) string { return env[k] }
+
+	if err = a.run(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	got := strings.Join(strings.Fields(output.String()), " ")
+	if want := "Package Statements Coverage github.com/alexaandru/stampli 54/58 93.1% Total 54/58 93.1% " +
+		"::notice title=Coverage::93.1% of statements"; got != want {
+		t.Errorf("Report = %q, want %q", got, want)
+	}
+
+	if summary, err := os.ReadFile(summaryFile); err != nil || !strings.Contains(string(summary), "| **Total** | **54/58** | **93.1%** |") {
+		t.Errorf("GitHub step summary = %q (%v), want the coverage table", summary, err)
+	}
+}
+
+func TestReportCommandHTML(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	coverageFile := copyCoverageSample(t, dir)
+	htmlDir := filepath.Join(dir, "html")
+
+	a, err := newApp(flag.NewFlagSet("test", flag.ContinueOnError),
+		[]string{"report", "-command", "true", "-coverage-file", coverageFile, "-html", htmlDir, "-quiet"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	a.dumpSink = io.Discard
+
+	if err = a.run(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for file, want := range map[string]string{"index.html": "<svg", filepath.Join("files", "main.go.html"): "func main() {"} {
+		if got, err := os.ReadFile(filepath.Join(htmlDir, file)); err != nil || !strings.Contains(string(got), want) {
+			t.Errorf("%s should contain %q, got %q (%v)", file, want, got, err)
+		}
+	}
+}
+
+func copyCoverageSample(t *testing.T, dir string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", "coverage-sample.out"))
+	if err != nil {
+		t.Fatalf("Failed to read testdata coverage file: %v", err)
+	}
+
+	filename := filepath.Join(dir, "coverage.out")
+	if err = os.WriteFile(filename, data, 0o600); err != nil {
+		t.Fatalf("Failed to create coverage file: %v", err)
+	}
+
+	return filename
+}
+
+func commandNamed(t *testing.T, name string) *command {
+	t.Helper()
+
+	cmd, _, err := parseCommand(flag.NewFlagSet("test", flag.ContinueOnError), []string{name})
+	if err != nil {
+		t.Fatalf("Unknown command %q: %v", name, err)
+	}
+
+	return cmd
+}
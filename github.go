@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/alexaandru/stampli/badge"
+)
+
+// githubActions integrates with GitHub Actions, when running in it: appends
+// the coverage table to the step summary, writes the step outputs and
+// annotates the files below the minimum coverage. See
+// https://docs.github.com/en/actions/reference/workflow-commands-for-github-actions
+func (a app) githubActions(badgeChanged bool) error {
+	if a.getenv == nil || a.getenv("GITHUB_ACTIONS") != "true" {
+		return nil
+	}
+
+	if err := appendFile(a.getenv("GITHUB_STEP_SUMMARY"), a.stepSummary()); err != nil {
+		return err
+	}
+
+	if err := appendFile(a.getenv("GITHUB_OUTPUT"), a.stepOutputs(badgeChanged)); err != nil {
+		return err
+	}
+
+	if a.BadgeType != badge.TypeTests {
+		fmt.Fprintf(a.dumpSink, "::notice title=Coverage::%.1f%% of statements\n", *a.CoveragePC) //nolint:errcheck // ok
+		a.annotateFiles()
+	}
+
+	return nil
+}
+
+// githubActionsCoverage integrates the commands measuring the coverage
+// without writing the badge (check and report) with GitHub Actions.
+func (a app) githubActionsCoverage() error {
+	a.BadgeType = badge.TypeCoverage
+
+	if err := a.githubActions(false); err != nil {
+		return fmt.Errorf("error writing the GitHub Actions outputs: %w", err)
+	}
+
+	return nil
+}
+
+// stepSummary returns the Markdown summary of the tests or coverage, the
+// latter with the coverage of each package, when known.
+func (a app) stepSummary() string {
+	if a.BadgeType == badge.TypeTests {
+		return fmt.Sprintf("### Tests: %s\n\n", a.tests.Summary())
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "### Coverage: %.1f%%\n\n", *a.CoveragePC)
+
+	if a.profile != nil {
+		b.WriteString("| Package | Statements | Coverage |\n| --- | ---: | ---: |\n")
+
+		for _, pkg := range a.profile.Packages() {
+			fmt.Fprintf(&b, "| %s | %d/%d | %.1f%% |\n", pkg.Name, pkg.Covered, pkg.Statements, pkg.Percent())
+		}
+
+		covered, total := a.profile.Statements()
+		fmt.Fprintf(&b, "| **Total** | **%d/%d** | **%.1f%%** |\n\n", covered, total, a.profile.Percent())
+	}
+
+	return b.String()
+}
+
+// stepOutputs returns the coverage (for coverage badges), color
+// and badge-changed step outputs.
+func (a app) stepOutputs(badgeChanged bool) string {
+	value, mode := 0.0, a.ColorMode
+
+	switch {
+	case a.BadgeType != badge.TypeTests:
+		value = *a.CoveragePC
+	case !a.tests.Failing():
+		value, mode = 100, badge.ColorModeDiscrete
+	}
+
+	outputs := fmt.Sprintf("color=%s\nbadge-changed=%t\n", a.Levels.ColorFor(value, mode), badgeChanged)
+	if a.BadgeType != badge.TypeTests {
+		outputs = fmt.Sprintf("coverage=%.1f\n", value) + outputs
+	}
+
+	return outputs
+}
+
+// annotateFiles emits a warning for each file below FileMinCoverage (or
+// MinCoverage), with its path relative to the module root.
+func (a app) annotateFiles() {
+	minCoverage := a.FileMinCoverage
+	if minCoverage == 0 {
+		minCoverage = a.MinCoverage
+	}
+
+	if a.profile == nil || minCoverage == 0 {
+		return
+	}
+
+	prefix := modulePath("go.mod") + "/"
+
+	for _, file := range a.profile.Files() {
+		if file.Percent() >= minCoverage {
+			continue
+		}
+
+		name := strings.TrimPrefix(file.Name, prefix)
+		fmt.Fprintf(a.dumpSink, "::warning file=%s,title=Low coverage::%s has %.1f%% coverage, below the %.1f%% minimum\n", //nolint:errcheck // ok
+			escapeProperty(name), escapeData(name), file.Percent(), minCoverage)
+	}
+}
+
+// modulePath returns the module path declared in the given go.mod file, if any.
+func modulePath(goMod string) string {
+	f, err := os.Open(goMod) //nolint:gosec // ok
+	if err != nil {
+		return ""
+	}
+
+	defer f.Close() //nolint:errcheck // read only
+
+	for scanner := bufio.NewScanner(f); scanner.Scan(); {
+		if path, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); ok {
+			return strings.Trim(strings.TrimSpace(path), `"`)
+		}
+	}
+
+	return ""
+}
+
+// appendFile appends content to the named file, if a name is given.
+func appendFile(name, content string) error {
+	if name == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644) //nolint:gosec,mnd // ok
+	if err != nil {
+		return err //nolint:wrapcheck // ok
+	}
+
+	if _, err = f.WriteString(content); err != nil {
+		f.Close() //nolint:errcheck,gosec // the write error matters more
+
+		return err //nolint:wrapcheck // ok
+	}
+
+	return f.Close() //nolint:wrapcheck // ok
+}
+
+// escapeData escapes the message of a workflow command.
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a property value of a workflow command.
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
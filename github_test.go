@@ -0,0 +1,116 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alexaandru/stampli/badge"
+)
+
+func TestGitHubActions(t *testing.T) {
+	t.Parallel()
+
+	profile, err := badge.ReadProfile(filepath.Join("testdata", "coverage-sample.out"))
+	if err != nil {
+		t.Fatalf("Failed to read testdata coverage file: %v", err)
+	}
+
+	coverage := profile.Percent()
+
+	tests := []struct {
+		name        string
+		config      badge.Config
+		profile     *badge.Profile
+		tests       *badge.TestStats
+		changed     bool
+		summary     string
+		outputs     string
+		annotations string
+	}{
+		{
+			name:    "Coverage badge",
+			config:  badge.Config{CoveragePC: &coverage, MinCoverage: 50, FileMinCoverage: 95},
+			profile: profile,
+			changed: true,
+			summary: "### Coverage: 93.1%\n\n| Package | Statements | Coverage |\n| --- | ---: | ---: |\n" +
+				"| github.com/alexaandru/stampli | 54/58 | 93.1% |\n| **Total** | **54/58** | **93.1%** |\n\n",
+			outputs: "coverage=93.1\ncolor=#44cc11\nbadge-changed=true\n",
+			annotations: "::notice title=Coverage::93.1% of statements\n" +
+				"::warning file=main.go,title=Low coverage::main.go has 93.1% coverage, below the 95.0% minimum\n",
+		},
+		{
+			name:        "Coverage without a profile",
+			config:      badge.Config{CoveragePC: &coverage, MinCoverage: 95},
+			summary:     "### Coverage: 93.1%\n\n",
+			outputs:     "coverage=93.1\ncolor=#44cc11\nbadge-changed=false\n",
+			annotations: "::notice title=Coverage::93.1% of statements\n",
+		},
+		{
+			name:    "Tests badge",
+			config:  badge.Config{BadgeType: badge.TypeTests},
+			tests:   &badge.TestStats{Passed: 10, Failed: 1},
+			summary: "### Tests: failing\n\n",
+			outputs: "color=#ff0001\nbadge-changed=false\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			env := map[string]string{
+				"GITHUB_ACTIONS":      "true",
+				"GITHUB_STEP_SUMMARY": filepath.Join(dir, "summary.md"),
+				"GITHUB_OUTPUT":       filepath.Join(dir, "output"),
+			}
+
+			var output strings.Builder
+
+			a := app{Config: tt.config, profile: tt.profile, tests: tt.tests, dumpSink: &output, getenv: func(k string) string { return env[k] }}
+			a.Levels = mustLevels(t, "85=#44cc11,=#ff0001")
+
+			if err := a.githubActions(tt.changed); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			for name, want := range map[string]string{"summary.md": tt.summary, "output": tt.outputs} {
+				if got, err := os.ReadFile(filepath.Join(dir, name)); err != nil || string(got) != want {
+					t.Errorf("%s = %q (%v), want %q", name, got, err, want)
+				}
+			}
+
+			if output.String() != tt.annotations {
+				t.Errorf("Annotations = %q, want %q", output.String(), tt.annotations)
+			}
+		})
+	}
+}
+
+func TestGitHubActionsDisabled(t *testing.T) {
+	t.Parallel()
+
+	var output strings.Builder
+
+	coverage := 50.0
+	a := app{dumpSink: &output, getenv: func(string) string { return "" }}
+	a.CoveragePC = &coverage
+
+	if err := a.githubActions(true); err != nil || output.Len() > 0 {
+		t.Errorf("githubActions() = %v, output %q, want nothing outside of GitHub Actions", err, output.String())
+	}
+}
+
+func TestEscapeProperty(t *testing.T) {
+	t.Parallel()
+
+	if got := escapeProperty("a:b,c%d\n"); got != "a%3Ab%2Cc%25d%0A" {
+		t.Errorf("escapeProperty() = %q", got)
+	}
+
+	if got := escapeData("a:b,c%d\n"); got != "a:b,c%25d%0A" {
+		t.Errorf("escapeData() = %q", got)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/alexaandru/stampli/badge"
+)
+
+// gitlab integrates with GitLab CI: prints the coverage line matched by the
+// job coverage regex (i.e. /^Coverage: \d+\.\d+%/) and writes the Cobertura
+// report, used to annotate the merge request diffs.
+func (a app) gitlab() error {
+	if a.BadgeType == badge.TypeTests {
+		return nil
+	}
+
+	if a.GitLab {
+		// Printed even when quiet, as it is explicitly asked for.
+		fmt.Fprintf(a.dumpSink, "Coverage: %.2f%%\n", a.totalCoverage()) //nolint:errcheck // ok
+	}
+
+	if a.CoberturaFile == "" {
+		return nil
+	}
+
+	if a.profile == nil {
+		return fmt.Errorf("the Cobertura report %w", errNoCoverageProfile)
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return err //nolint:wrapcheck // ok
+	}
+
+	var buf bytes.Buffer
+
+	opts := badge.CoberturaOptions{Module: modulePath("go.mod"), Source: dir, Timestamp: time.Now()}
+	if err = a.profile.WriteCobertura(&buf, opts); err != nil {
+		return err //nolint:wrapcheck // ok
+	}
+
+	if err = os.WriteFile(a.CoberturaFile, buf.Bytes(), 0o644); err != nil { //nolint:gosec,mnd // ok
+		return fmt.Errorf("error writing the Cobertura report: %w", err)
+	}
+
+	if !a.Quiet {
+		fmt.Fprintf(a.dumpSink, "Cobertura report written: %s\n", a.CoberturaFile) //nolint:errcheck // ok
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alexaandru/stampli/badge"
+)
+
+func TestGitLab(t *testing.T) {
+	t.Parallel()
+
+	profile, err := badge.ReadProfile(filepath.Join("testdata", "coverage-sample.out"))
+	if err != nil {
+		t.Fatalf("Failed to read testdata coverage file: %v", err)
+	}
+
+	coverage := 85.4
+
+	tests := []struct {
+		name      string
+		config    badge.Config
+		profile   *badge.Profile
+		cobertura bool
+		output    string
+		wantErr   error
+	}{
+		{name: "Coverage line", config: badge.Config{GitLab: true, Quiet: true, CoveragePC: &coverage}, output: "Coverage: 85.40%\n"},
+		{name: "Coverage line of the profile", config: badge.Config{GitLab: true, Quiet: true}, profile: profile, output: "Coverage: 93.10%\n"},
+		{name: "Tests badge", config: badge.Config{GitLab: true, BadgeType: badge.TypeTests}},
+		{name: "Cobertura report", config: badge.Config{Quiet: true}, profile: profile, cobertura: true},
+		{name: "Cobertura without profile", config: badge.Config{CoveragePC: &coverage}, cobertura: true, wantErr: errNoCoverageProfile},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var output strings.Builder
+
+			a := app{Config: tt.config, profile: tt.profile, dumpSink: &output}
+			if tt.cobertura {
+				a.CoberturaFile = filepath.Join(t.TempDir(), "coverage.xml")
+			}
+
+			if err := a.gitlab(); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+
+			if output.String() != tt.output {
+				t.Errorf("Output = %q, want %q", output.String(), tt.output)
+			}
+
+			if !tt.cobertura || tt.wantErr != nil {
+				return
+			}
+
+			got, err := os.ReadFile(a.CoberturaFile)
+			if err != nil {
+				t.Fatalf("Failed to read the Cobertura report: %v", err)
+			}
+
+			if want := `<class name="main.go" filename="main.go" line-rate="`; !strings.Contains(string(got), want) {
+				t.Errorf("Cobertura report should contain %q, got:\n%s", want, got)
+			}
+		})
+	}
+}
@@ -1,3 +1,9 @@
 module github.com/alexaandru/stampli
 
 go 1.24.5
+
+require (
+	github.com/BurntSushi/toml v1.5.0
+	golang.org/x/image v0.25.0
+	gopkg.in/yaml.v3 v3.0.1
+)
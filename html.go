@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/alexaandru/stampli/badge"
+)
+
+// writeHTMLReport writes the HTML coverage report to HTMLDir, if set, with
+// the given badge on top (rendered here, as a coverage badge, if empty).
+func (a app) writeHTMLReport(svg string) error {
+	if a.HTMLDir == "" || a.BadgeType == badge.TypeTests {
+		return nil
+	}
+
+	if a.profile == nil {
+		return fmt.Errorf("the HTML report %w", errNoCoverageProfile)
+	}
+
+	if svg == "" {
+		if err := a.LoadTemplate(); err != nil {
+			return err //nolint:wrapcheck // ok
+		}
+
+		var err error
+		if svg, err = a.generateBadge(); err != nil {
+			return fmt.Errorf("error generating badge: %w", err)
+		}
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return err //nolint:wrapcheck // ok
+	}
+
+	r := badge.HTMLReport{
+		Profile:   a.profile,
+		Levels:    a.Levels,
+		ColorMode: a.ColorMode,
+		Badge:     svg,
+		Module:    modulePath("go.mod"),
+		Source:    dir,
+	}
+
+	if err = r.Write(a.HTMLDir); err != nil {
+		return fmt.Errorf("error writing the HTML report: %w", err)
+	}
+
+	if !a.Quiet {
+		fmt.Fprintf(a.dumpSink, "HTML report written: %s\n", filepath.Join(a.HTMLDir, "index.html")) //nolint:errcheck // ok
+	}
+
+	return nil
+}
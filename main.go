@@ -1,59 +1,89 @@
 package main
 
 import (
-	"bufio"
 	"bytes"
-	_ "embed"
+	"cmp"
 	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
-	"text/template"
+	"text/tabwriter"
+	"time"
+	"unicode"
+
+	"github.com/alexaandru/stampli/badge"
 )
 
 //nolint:govet,recvcheck // ok
 type app struct {
-	config
+	badge.Config
 
 	defaultConfig     string
 	defaultConfigFile string
+	dumpFormat        string
+	dumpSchema        bool
+	command           *command
+	args              []string // The positional arguments of the command.
+	skipTests         bool     // Use the existing coverage profile, without running the tests.
+	initOpts          initOptions
+	addr              string // The serve command address.
+	renderOpts        renderOptions
+	watchMode         bool
+	watchInterval     time.Duration
+	profileName       string
+	allProfiles       bool
+	fileConfig        badge.Config  // The config files layer, profiles are applied onto.
+	overrides         *badge.Config // The environment variables and flags layer,
+	overrideKeys      []string      // for these keys only.
+	getenv            func(string) string
+	sources           map[string]string // The source of each config key value.
 	dumpSink          io.Writer
+	tests             *badge.TestStats
+	profile           *badge.Profile
 }
 
-type config struct {
-	Levels       Levels   `json:"levels,omitzero"`
-	CoveragePC   *float64 `json:"-"`
-	TestCommand  string   `json:"testCommand"`
-	OutputFile   string   `json:"outputFile"`
-	ConfigFile   string   `json:"-"`
-	Template     string   `json:"template"`
-	DumpTemplate bool     `json:"dumpTemplate"`
-	DumpConfig   bool     `json:"dumpConfig"`
-	Quiet        bool     `json:"quiet"`
-	AutoClean    bool     `json:"autoClean"`
-}
-
-const defaultConfigFile = "stampli.json"
-
-//go:embed coverage-badge.tmpl
-var defaultTemplate string
-
-//go:embed stampli.json
-var defaultConfig string
+const (
+	defaultConfigFile = "stampli.json"
+	effectiveFormat   = "effective" // The -dump-format for the merged config.
+)
 
 var (
-	errEmptyCommand      = errors.New("empty command")
-	errInvalidFileFormat = errors.New("invalid coverage file format")
+	errEmptyCommand        = errors.New("empty command")
+	errCoverageFileMissing = errors.New("coverage file not found")
+	errNoTestEvents        = errors.New("no go test -json events in the test command output")
+	errLowContrast         = errors.New("levels with low contrast colors")
+	errBelowMinCoverage    = errors.New("coverage below the minimum")
+	errTestsFailing        = errors.New("failing tests")
+	errNoProfiles          = errors.New("no profiles in the configuration")
+	errTestsNotRun         = errors.New("the tests badge requires running the tests (use the run command)")
+	errNoCoverageProfile   = errors.New("requires a coverage profile (not just -coverage)")
+	errUnexpectedArgs      = errors.New("unexpected arguments")
+	errUnterminatedQuote   = errors.New("unterminated quote or escape in the test command")
 )
 
+// coverProfileRe matches the coverprofile flag in all the forms go test accepts
+// (-coverprofile=x, --coverprofile x, -test.coverprofile=x), including when it
+// is passed via an inline GOFLAGS=... assignment.
+var coverProfileRe = regexp.MustCompile(`(?:^|[\s='"])--?(?:test\.)?coverprofile(?:=|\s+)(\S+)`)
+
+// envAssignmentRe matches a VAR=value command word.
+var envAssignmentRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+
 func main() {
 	a, err := newApp(flag.CommandLine, os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
@@ -66,245 +96,871 @@ func main() {
 }
 
 func newApp(fs *flag.FlagSet, args []string) (a app, err error) {
-	a.defaultConfig = defaultConfig
+	a.defaultConfig = badge.DefaultConfig()
 	a.defaultConfigFile = defaultConfigFile
 	a.dumpSink = os.Stdout
+	a.getenv = os.Getenv
+
+	if a.command, args, err = parseCommand(fs, args); err != nil {
+		return
+	}
+
 	err = a.loadConfig(fs, args)
 
 	return
 }
 
-func (c *config) merge(other *config) (err error) {
-	js, err := json.Marshal(other)
-	if err != nil {
-		return fmt.Errorf("failed to marshal other config: %w", err)
+// flagKeys maps the flags to the config keys they set.
+var flagKeys = map[string]string{ //nolint:gochecknoglobals // ok
+	"command":       "testCommand",
+	"coverage-file": "coverageFile",
+	"badge-type":    "badgeType",
+	"color-mode":    "colorMode",
+	"output":        "outputFile",
+	"min":           "minCoverage",
+	"file-min":      "fileMinCoverage",
+	"markdown":      "markdownFile",
+	"baseline":      "baselineFile",
+	"gitlab":        "gitlab",
+	"cobertura":     "coberturaFile",
+	"html":          "htmlDir",
+	"metrics":       "metricsFile",
+	"template":      "template",
+	"levels":        "levels",
+	"dark-levels":   "darkLevels",
+	"dump-template": "dumpTemplate",
+	"dump-config":   "dumpConfig",
+	"quiet":         "quiet",
+	"auto-clean":    "autoClean",
+}
+
+// loadConfig layers the configuration: embedded defaults < config
+// file < profile < STAMPLI_* environment variables < command line flags.
+func (a *app) loadConfig(fs *flag.FlagSet, args []string) error {
+	cfg := &a.Config
+
+	// Start with embedded defaults.
+	if err := json.Unmarshal([]byte(a.defaultConfig), cfg); err != nil {
+		return fmt.Errorf("failed to load embedded defaults: %w", err)
 	}
 
-	if err = json.Unmarshal(js, c); err != nil {
-		return fmt.Errorf("failed to unmarshal into current config: %w", err)
+	if a.command == nil {
+		a.command = commands[0]
 	}
 
-	if other.CoveragePC != nil {
-		c.CoveragePC = other.CoveragePC
+	cfg2 := &badge.Config{}
+	groups := a.command.flags
+
+	if groups&configFlags != 0 {
+		fs.StringVar(&cfg.ConfigFile, "config", a.defaultConfigFile,
+			"Path to the configuration file (JSON, YAML or TOML, detected by extension)")
+		fs.StringVar(&a.profileName, "profile", "", "Configuration profile to apply (see profiles in the configuration file)")
+		fs.BoolVar(&a.allProfiles, "all-profiles", false, "Run the command for all the configuration profiles")
 	}
 
-	return
-}
+	if groups&commandFlags != 0 {
+		fs.StringVar(&cfg2.TestCommand, "command", cfg.TestCommand, "Command to run tests and generate coverage")
+		fs.BoolVar(&cfg2.AutoClean, "auto-clean", cfg.AutoClean, "Automatically clean up coverage files after generating the badge")
+	}
 
-func (c *config) loadTemplate() error {
-	if c.Template != "" {
-		data, err := os.ReadFile(c.Template)
-		if err != nil {
-			return fmt.Errorf("could not read template file %s: %w", c.Template, err)
-		}
+	if groups&coverageFileFlags != 0 {
+		fs.StringVar(&cfg2.CoverageFile, "coverage-file", cfg.CoverageFile,
+			"Coverage profile to parse (default: detected from the test command, then GOFLAGS, then coverage.out)")
+	}
 
-		c.Template = string(data)
-	} else {
-		c.Template = defaultTemplate
+	if groups&badgeFlags != 0 {
+		fs.StringVar(&cfg2.BadgeType, "badge-type", cfg.BadgeType,
+			`Badge type: "coverage" or "tests" (the latter needs a test command using -json)`)
+		fs.StringVar(&cfg2.ColorMode, "color-mode", cfg.ColorMode,
+			`Levels color mode: "discrete" or "gradient" (interpolates between the level colors)`)
+		fs.StringVar(&cfg2.OutputFile, "output", cfg.OutputFile, "Output SVG file path")
+		fs.StringVar(&cfg2.Template, "template", cfg.Template, "Path to custom SVG template file (optional)")
+		fs.Var(&cfg2.Levels, "levels", fmt.Sprintf("Coverage levels and colors (default %q)", cfg.Levels.String()))
+		fs.Var(&cfg2.DarkLevels, "dark-levels", "Coverage levels and colors for viewers preferring a dark color scheme (optional)")
 	}
 
-	return nil
-}
+	if groups&minFlags != 0 {
+		fs.Float64Var(&cfg2.MinCoverage, "min", cfg.MinCoverage,
+			"Minimum coverage percentage, failing (after generating the badge, if any) if not met")
+		fs.Float64Var(&cfg2.FileMinCoverage, "file-min", cfg.FileMinCoverage,
+			"Minimum coverage percentage of each file, annotated in GitHub Actions when not met (default: -min)")
+	}
 
-func (a *app) loadConfig(fs *flag.FlagSet, args []string) error {
-	cfg := &a.config
+	if groups&reportFlags != 0 {
+		fs.StringVar(&cfg2.MarkdownFile, "markdown", cfg.MarkdownFile, "Markdown coverage report file path, i.e. for a PR comment (optional)")
+		fs.StringVar(&cfg2.BaselineFile, "baseline", cfg.BaselineFile,
+			"Baseline coverage profile the Markdown report compares to, i.e. the one of the target branch (optional)")
+		fs.StringVar(&cfg2.HTMLDir, "html", cfg.HTMLDir, "HTML coverage report directory (optional)")
+		fs.StringVar(&cfg2.MetricsFile, "metrics", cfg.MetricsFile,
+			"OpenMetrics coverage metrics file path, i.e. for the node_exporter textfile collector (optional)")
+	}
 
-	// Start with embedded defaults.
-	if err := json.Unmarshal([]byte(a.defaultConfig), cfg); err != nil {
-		return fmt.Errorf("failed to load embedded defaults: %w", err)
+	if groups&gitlabFlags != 0 {
+		fs.BoolVar(&cfg2.GitLab, "gitlab", cfg.GitLab, "Print a stable \"Coverage: 85.40%\" line for the GitLab CI coverage regex, even when quiet")
+		fs.StringVar(&cfg2.CoberturaFile, "cobertura", cfg.CoberturaFile, "Cobertura XML coverage report file path, i.e. for GitLab (optional)")
 	}
 
-	cfg2 := &config{}
+	if groups&serveFlags != 0 {
+		fs.StringVar(&a.addr, "addr", defaultAddr, "Address to serve the badges on")
+	}
+
+	if groups&renderFlags != 0 {
+		a.renderOpts.register(fs)
+	}
+
+	if groups&watchFlags != 0 {
+		fs.BoolVar(&a.watchMode, "watch", false, "Run again whenever the Go files or the coverage file change, until interrupted")
+		fs.DurationVar(&a.watchInterval, "watch-interval", defaultWatchInterval, "Interval to check for changes at, with -watch")
+	}
+
+	if groups&dumpFlags != 0 {
+		fs.BoolVar(&cfg2.DumpTemplate, "dump-template", cfg.DumpTemplate, "Dump the default SVG template to stdout and exit")
+		fs.BoolVar(&cfg2.DumpConfig, "dump-config", cfg.DumpConfig, "Dump the default configuration (as JSON) to stdout and exit")
+		fs.Func("dump-format", "Dump the configuration in the given `format` and exit: json, yaml or toml for the default one, "+
+			"or effective for the merged configuration and the source of each value", func(value string) error {
+			switch value {
+			case badge.FormatJSON, badge.FormatYAML, badge.FormatTOML, effectiveFormat:
+				a.dumpFormat = value
+				return nil
+			default:
+				return fmt.Errorf("%w: %q", badge.ErrUnknownFormat, value)
+			}
+		})
+		fs.BoolVar(&a.dumpSchema, "dump-schema", false, "Dump the JSON Schema of the configuration file to stdout and exit")
+	}
+
+	if groups&initFlags != 0 {
+		a.initOpts.register(fs)
+	}
 
-	fs.StringVar(&cfg2.TestCommand, "command", cfg.TestCommand, "Command to run tests and generate coverage")
-	fs.StringVar(&cfg2.OutputFile, "output", cfg.OutputFile, "Output SVG file path")
-	fs.StringVar(&cfg.ConfigFile, "config", a.defaultConfigFile, "Path to JSON configuration file")
-	fs.StringVar(&cfg2.Template, "template", cfg.Template, "Path to custom SVG template file (optional)")
-	fs.Var(&cfg2.Levels, "levels", fmt.Sprintf("Coverage levels and colors (default %q)", cfg.Levels.String()))
-	fs.BoolVar(&cfg2.DumpTemplate, "dump-template", cfg.DumpTemplate, "Dump the default SVG template to stdout and exit")
-	fs.BoolVar(&cfg2.DumpConfig, "dump-config", cfg.DumpConfig, "Dump the default configuration to stdout and exit")
 	fs.BoolVar(&cfg2.Quiet, "quiet", cfg.Quiet, "Suppress output messages (only errors will be printed)")
-	fs.BoolVar(&cfg2.AutoClean, "auto-clean", cfg.AutoClean, "Automatically clean up coverage files after generating the badge")
 
 	var (
 		coverageFlag float64
 		coverageSet  bool
 	)
 
-	fs.Func("coverage", "Coverage percentage to use directly (skips running tests)", func(s string) error {
-		val, err := strconv.ParseFloat(s, 64)
+	if groups&coverageFlags != 0 {
+		fs.Func("coverage", "Coverage percentage to use directly (skips running tests)", func(s string) error {
+			val, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return err //nolint:wrapcheck // ok
+			}
+
+			coverageFlag = val
+			coverageSet = true
+
+			return nil
+		})
+	}
+
+	fs.Usage = func() { a.command.usage(fs) }
+
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	a.args = fs.Args()
+
+	if n := len(strings.Fields(a.command.args)); len(a.args) > n {
+		return fmt.Errorf("%w: %q (see stampli help %s)", errUnexpectedArgs, a.args[n:], a.command.name)
+	}
+
+	setFlags := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+
+	a.sources = map[string]string{}
+	for _, key := range flagKeys {
+		a.sources[key] = "default"
+	}
+
+	if groups&configFlags != 0 {
+		if err := a.loadConfigFile(setFlags["config"]); err != nil {
+			return err
+		}
+	}
+
+	if err := a.applyEnv(fs, setFlags); err != nil {
+		return err
+	}
+
+	if coverageSet {
+		cfg.CoveragePC = &coverageFlag
+	}
+
+	keys := []string{}
+
+	fs.Visit(func(f *flag.Flag) {
+		if key, ok := flagKeys[f.Name]; ok {
+			keys = append(keys, key)
+
+			if setFlags[f.Name] {
+				a.sources[key] = "flag -" + f.Name
+			}
+		}
+	})
+
+	a.fileConfig, a.overrides, a.overrideKeys = *cfg, cfg2, keys
+
+	return a.useProfile(a.profileName)
+}
+
+// useProfile layers the named profile, if any, between the config
+// files and the environment variables and flags.
+func (a *app) useProfile(name string) error {
+	a.Config = a.fileConfig
+
+	if name != "" {
+		keys, err := a.ApplyProfile(name)
 		if err != nil {
 			return err //nolint:wrapcheck // ok
 		}
 
-		coverageFlag = val
-		coverageSet = true
+		for _, key := range keys {
+			if !slices.Contains(a.overrideKeys, key) {
+				a.sources[key] = "profile " + name
+			}
+		}
+	}
+
+	return a.MergeKeys(a.overrides, a.overrideKeys...) //nolint:wrapcheck // ok
+}
+
+// loadConfigFile loads the config file given via -config, else via
+// STAMPLI_CONFIG, else the discovered ones, if any (see discoverConfigFiles).
+func (a *app) loadConfigFile(flagSet bool) error {
+	cfg := &a.Config
+
+	if v := a.env("config"); v != "" && !flagSet {
+		cfg.ConfigFile = v
+	}
 
+	if cfg.ConfigFile == "" {
 		return nil
-	})
+	}
 
-	if err := fs.Parse(args); err != nil {
-		return fmt.Errorf("failed to parse flags: %w", err)
+	// The default config files are optional, a non-default one must exist.
+	files := []string{cfg.ConfigFile}
+	if cfg.ConfigFile == a.defaultConfigFile {
+		files, cfg.ConfigFile = a.discoverConfigFiles(), ""
 	}
 
-	if coverageSet {
-		cfg2.CoveragePC = &coverageFlag
+	for _, filename := range files {
+		if err := cfg.LoadFile(filename); err != nil {
+			return err //nolint:wrapcheck // ok
+		}
+
+		keys, err := badge.ConfigFileKeys(filename)
+		if err != nil {
+			return err //nolint:wrapcheck // ok
+		}
+
+		for _, key := range keys {
+			a.sources[key] = "config file " + filename
+		}
+
+		cfg.ConfigFile = filename
+	}
+
+	return nil
+}
+
+// discoverConfigFiles returns the existing default config files, in the order
+// they are to be loaded: the user one, from $XDG_CONFIG_HOME/stampli/ (or
+// ~/.config/stampli/), then the project one, the nearest one found from the
+// current directory up to the repository root (the first directory with a
+// .git or go.mod).
+func (a *app) discoverConfigFiles() (files []string) {
+	base := filepath.Base(a.defaultConfigFile)
+
+	if dir := a.userConfigDir(); dir != "" {
+		if filename, ok := findConfigFile(filepath.Join(dir, "stampli", base)); ok {
+			files = append(files, filename)
+		}
+	}
+
+	cwd, err := filepath.Abs(".")
+	if err != nil {
+		return
 	}
 
-	if cfg.ConfigFile != "" {
-		if data, err := os.ReadFile(cfg.ConfigFile); err == nil {
-			if err = json.Unmarshal(data, cfg); err != nil {
-				return fmt.Errorf("failed to parse config file %s: %w", cfg.ConfigFile, err)
+	dir := filepath.Join(cwd, filepath.Dir(a.defaultConfigFile))
+	if filepath.IsAbs(a.defaultConfigFile) {
+		dir = filepath.Dir(a.defaultConfigFile)
+	}
+
+	for {
+		if filename, ok := findConfigFile(filepath.Join(dir, base)); ok {
+			// Keep the paths relative to the current directory, as given.
+			if rel, err := filepath.Rel(cwd, filename); err == nil && !filepath.IsAbs(a.defaultConfigFile) {
+				filename = rel
 			}
-		} else if cfg.ConfigFile != a.defaultConfigFile {
-			// Otherwise, if a non-default config file was requested, it must exist.
-			return fmt.Errorf("failed to load config file %s: %w", cfg.ConfigFile, err)
+
+			if slices.Contains(files, filename) {
+				return
+			}
+
+			return append(files, filename)
 		}
+
+		if isRepoRoot(dir) {
+			return
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return
+		}
+
+		dir = parent
+	}
+}
+
+func (a *app) userConfigDir() string {
+	if a.getenv == nil {
+		return ""
+	}
+
+	if dir := a.getenv("XDG_CONFIG_HOME"); dir != "" {
+		return dir
 	}
 
-	return cfg.merge(cfg2)
+	if home := a.getenv("HOME"); home != "" {
+		return filepath.Join(home, ".config")
+	}
+
+	return ""
+}
+
+func isRepoRoot(dir string) bool {
+	for _, marker := range []string{".git", "go.mod"} {
+		if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+			return true
+		}
+	}
+
+	return false
 }
 
-func (a app) run() (err error) {
-	if a.DumpTemplate {
-		fmt.Fprint(a.dumpSink, defaultTemplate) //nolint:errcheck // ok
+// applyEnv sets the flags not given on the command line from their STAMPLI_*
+// environment variables, if set (i.e. STAMPLI_OUTPUT for -output).
+func (a *app) applyEnv(fs *flag.FlagSet, setFlags map[string]bool) (err error) {
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || setFlags[f.Name] || f.Name == "config" || strings.HasPrefix(f.Name, "dump-") {
+			return
+		}
+
+		v := a.env(f.Name)
+		if v == "" {
+			return
+		}
+
+		if err = fs.Set(f.Name, v); err != nil {
+			err = fmt.Errorf("invalid %s value %q: %w", envName(f.Name), v, err)
+			return
+		}
+
+		if key, ok := flagKeys[f.Name]; ok {
+			a.sources[key] = "env " + envName(f.Name)
+		}
+	})
+
+	return
+}
+
+// env returns the value of the environment variable of the given flag.
+func (a *app) env(flagName string) string {
+	if a.getenv == nil {
+		return ""
+	}
+
+	return a.getenv(envName(flagName))
+}
+
+func envName(flagName string) string {
+	return "STAMPLI_" + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+func (a app) run() error {
+	if a.command == nil {
+		a.command = commands[0]
+	}
+
+	if a.command.flags&dumpFlags != 0 {
+		if done, err := a.dump(); done {
+			return err
+		}
+	}
+
+	if a.watchMode {
+		return a.watch()
+	}
+
+	if a.allProfiles {
+		return a.runProfiles()
+	}
+
+	return a.command.run(&a)
+}
+
+// dump handles the -dump-* flags, reporting whether any was set.
+func (a app) dump() (bool, error) {
+	switch {
+	case a.DumpTemplate:
+		fmt.Fprint(a.dumpSink, badge.DefaultTemplate(a.BadgeType)) //nolint:errcheck // ok
+	case a.dumpFormat == effectiveFormat:
+		return true, a.dumpEffectiveConfig()
+	case a.DumpConfig || a.dumpFormat != "":
+		config, err := badge.DefaultConfigAs(cmp.Or(a.dumpFormat, badge.FormatJSON))
+		if err != nil {
+			return true, err //nolint:wrapcheck // ok
+		}
+
+		fmt.Fprint(a.dumpSink, config) //nolint:errcheck // ok
+	case a.dumpSchema:
+		fmt.Fprint(a.dumpSink, badge.Schema()) //nolint:errcheck // ok
+	default:
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// generate runs the tests, unless skipTests is set, and writes the badge.
+func (a *app) generate() (err error) {
+	if err = a.Config.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	if err = a.LoadTemplate(); err != nil {
+		return //nolint:wrapcheck // ok
+	}
+
+	if !a.Quiet {
+		a.warnContrast()
+	}
+
+	switch a.BadgeType {
+	case "", badge.TypeCoverage:
+		if err = a.loadCoverage(); err != nil {
+			return
+		}
+	case badge.TypeTests:
+		if a.skipTests {
+			return errTestsNotRun
+		}
+
+		if err = a.runTestsAndGetStats(); err != nil {
+			return fmt.Errorf("error getting test results: %w", err)
+		}
+	default:
+		return fmt.Errorf("%w: %q", badge.ErrUnknownType, a.BadgeType)
+	}
+
+	svg, err := a.generateBadge()
+	if err != nil {
+		return fmt.Errorf("error generating badge: %w", err)
+	}
+
+	previous, _ := os.ReadFile(a.OutputFile) //nolint:errcheck // a missing badge is a changed one
+
+	if err = a.writeBadgeFile(svg); err != nil {
+		return fmt.Errorf("error writing badge file: %w", err)
+	}
+
+	if !a.Quiet {
+		if a.BadgeType == badge.TypeTests {
+			fmt.Printf("Tests badge generated: %s (%s)\n", a.OutputFile, a.tests.Summary()) //nolint:forbidigo // ok
+		} else {
+			fmt.Printf("Coverage badge generated: %s (%.1f%% coverage)\n", a.OutputFile, *a.CoveragePC) //nolint:forbidigo // ok
+		}
+	}
+
+	if err = a.githubActions(string(previous) != svg); err != nil {
+		return fmt.Errorf("error writing the GitHub Actions outputs: %w", err)
+	}
+
+	if err = a.writeMarkdown(); err != nil {
 		return
 	}
 
-	if a.DumpConfig {
-		fmt.Fprint(a.dumpSink, defaultConfig) //nolint:errcheck // ok
+	if err = a.writeHTMLReport(svg); err != nil {
 		return
 	}
 
-	if err = a.loadTemplate(); err != nil {
+	if err = a.writeMetrics(); err != nil {
 		return
 	}
 
-	if a.CoveragePC == nil {
-		var coverage float64
+	if err = a.gitlab(); err != nil {
+		return
+	}
+
+	if a.BadgeType == badge.TypeTests {
+		if a.tests.Failing() {
+			// The (failing) badge is written, but CI should still fail.
+			return fmt.Errorf("%w: %s", errTestsFailing, a.tests.Summary())
+		}
+
+		return nil
+	}
+
+	return a.checkMin()
+}
+
+// loadCoverage sets the coverage, unless given, from the tests coverage profile.
+func (a *app) loadCoverage() error {
+	if a.CoveragePC != nil {
+		return nil
+	}
+
+	coverage, err := a.runTestsAndGetCoverage()
+	if err != nil {
+		return fmt.Errorf("error getting coverage: %w", err)
+	}
+
+	a.CoveragePC = &coverage
+
+	return nil
+}
+
+func (a app) checkMin() error {
+	if *a.CoveragePC < a.MinCoverage {
+		return fmt.Errorf("%w: %.1f%% < %.1f%%", errBelowMinCoverage, *a.CoveragePC, a.MinCoverage)
+	}
+
+	return nil
+}
+
+// runProfiles runs each of the profiles in turn, reporting the errors of all.
+func (a app) runProfiles() error {
+	names := a.ProfileNames()
+	if len(names) == 0 {
+		return errNoProfiles
+	}
+
+	errs := []error{}
+
+	for _, name := range names {
+		p := a
+		p.allProfiles, p.profileName, p.sources = false, name, maps.Clone(a.sources)
+
+		err := p.useProfile(name)
+		if err == nil {
+			err = p.run()
+		}
 
-		coverage, err = a.runTestsAndGetCoverage()
 		if err != nil {
-			return fmt.Errorf("error getting coverage: %w", err)
+			errs = append(errs, fmt.Errorf("profile %s: %w", name, err))
 		}
+	}
 
-		a.CoveragePC = &coverage
+	return errors.Join(errs...)
+}
+
+// dumpEffectiveConfig dumps the merged config, with the source of each value.
+func (a app) dumpEffectiveConfig() error {
+	js, err := json.Marshal(a.Config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	badge, err := a.generateBadge()
+	var values map[string]json.RawMessage
+	if err = json.Unmarshal(js, &values); err != nil {
+		return fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	// The keys with a source are listed even when zero (i.e. -min 0).
+	sourced, err := a.Values(slices.Collect(maps.Keys(a.sources))...)
 	if err != nil {
-		return fmt.Errorf("error generating badge: %w", err)
+		return err //nolint:wrapcheck // ok
 	}
 
-	if err = a.writeBadgeFile(badge); err != nil {
-		return fmt.Errorf("error writing badge file: %w", err)
+	maps.Copy(values, sourced)
+
+	w := tabwriter.NewWriter(a.dumpSink, 0, 0, 2, ' ', 0) //nolint:mnd // ok
+
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", key, values[key], cmp.Or(a.sources[key], "default")) //nolint:errcheck // ok
+	}
+
+	return w.Flush() //nolint:wrapcheck // ok
+}
+
+// findConfigFile returns the first existing one of filename and its .yaml,
+// .yml and .toml variants, if any.
+func findConfigFile(filename string) (string, bool) {
+	base := strings.TrimSuffix(filename, filepath.Ext(filename))
+
+	for _, ext := range []string{filepath.Ext(filename), ".yaml", ".yml", ".toml"} {
+		if _, err := os.Stat(base + ext); err == nil {
+			return base + ext, true
+		}
+	}
+
+	return "", false
+}
+
+// validate checks the configuration and reports the contrast
+// issues of the configured levels.
+func (a *app) validate() error {
+	if err := a.Config.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	issues := a.contrastIssues()
+	for _, issue := range issues {
+		fmt.Fprintln(a.dumpSink, issue) //nolint:errcheck // ok
+	}
+
+	if len(issues) > 0 {
+		return fmt.Errorf("%w: %d issue(s) found", errLowContrast, len(issues))
 	}
 
 	if !a.Quiet {
-		fmt.Printf("Coverage badge generated: %s (%.1f%% coverage)\n", a.OutputFile, *a.CoveragePC) //nolint:forbidigo // ok
+		fmt.Fprintln(a.dumpSink, "Configuration is valid") //nolint:errcheck // ok
+	}
+
+	return nil
+}
+
+// warnContrast warns about the contrast issues of the levels.
+func (a app) warnContrast() {
+	for _, issue := range a.contrastIssues() {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", issue)
+	}
+}
+
+func (a app) contrastIssues() (issues []string) {
+	for _, issue := range a.Levels.CheckContrast() {
+		issues = append(issues, issue.String())
+	}
+
+	for _, issue := range a.DarkLevels.CheckContrast() {
+		issues = append(issues, "dark "+issue.String())
 	}
 
 	return
 }
 
-func (a app) runTestsAndGetCoverage() (_ float64, err error) {
-	command := a.TestCommand
+// runTests runs the test command, recording the test results
+// if the command emitted a go test -json event stream.
+func (a *app) runTests() error {
+	words, err := splitWords(a.TestCommand)
+	if err != nil {
+		return err
+	}
 
-	parts := strings.Fields(command)
+	env, parts := commandEnv(words)
 	if len(parts) == 0 {
-		return 0, errEmptyCommand
+		return errEmptyCommand
 	}
 
 	cmd := exec.Command(parts[0], parts[1:]...) //nolint:noctx,gosec // yes, we actually do want end users to be able to drive this
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 
 	output, err := cmd.CombinedOutput()
+	a.tests = badge.ParseTestEvents(bytes.NewReader(output))
+
 	if err != nil {
-		return 0, fmt.Errorf("test command failed: %w\nOutput: %s", err, output)
+		return fmt.Errorf("test command failed: %w\nOutput: %s", err, output)
 	}
 
-	coverageFile := "coverage.out"
+	return nil
+}
 
-	if strings.Contains(command, "-coverprofile=") {
-		re := regexp.MustCompile(`-coverprofile=(\S+)`)
-		if matches := re.FindStringSubmatch(command); len(matches) > 1 {
-			coverageFile = matches[1]
+// commandEnv splits the leading VAR=value words of the command off, as a shell
+// would (i.e. GOFLAGS="-count=1 -coverprofile=x" go test ./...).
+func commandEnv(words []string) (env, args []string) {
+	for i, word := range words {
+		if !envAssignmentRe.MatchString(word) {
+			return env, words[i:]
 		}
+
+		env = append(env, word)
 	}
 
-	if a.AutoClean {
-		defer func() {
-			err = errors.Join(err, os.Remove(coverageFile))
-		}()
+	return env, nil
+}
+
+// splitWords splits the command into words as a shell would, minus the
+// expansions: the single quoted text is literal, backslashes escape any
+// character when unquoted, and only " and \\ in double quotes.
+func splitWords(command string) (words []string, err error) {
+	var (
+		word    strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range command {
+		switch {
+		case escaped:
+			if quote == '"' && r != '"' && r != '\\' {
+				word.WriteRune('\\') // Only those are escaped in double quotes.
+			}
+
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			word.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+			}
+
+			inWord = false
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("%w: %s", errUnterminatedQuote, command)
+	}
+
+	if inWord {
+		words = append(words, word.String())
 	}
 
-	return parseCoverageFile(coverageFile)
+	return words, nil
 }
 
-func parseCoverageFile(filename string) (cov float64, err error) {
-	cmd := exec.Command("go", "tool", "cover", "-func="+filename) //nolint:gosec,noctx // safe
+// runTestsAndGetStats runs the test command for the tests badge. Failing tests
+// are not an error here, as long as the command reported them via -json.
+func (a *app) runTestsAndGetStats() error {
+	err := a.runTests()
 
-	output, err := cmd.Output()
-	if err != nil {
-		return 0, fmt.Errorf("%w: %w", errInvalidFileFormat, err)
+	switch {
+	case a.tests == nil:
+		return errors.Join(errNoTestEvents, err)
+	case err != nil && !a.tests.Failing():
+		return err
 	}
 
-	var lastLine string
+	return nil
+}
 
-	scanner := bufio.NewScanner(bytes.NewReader(output))
+// runTestsAndGetCoverage runs the test command, unless skipTests is set,
+// and reads the coverage profile it wrote.
+func (a *app) runTestsAndGetCoverage() (_ float64, err error) {
+	if !a.skipTests {
+		if err = a.runTests(); err != nil {
+			return 0, err
+		}
+	}
 
-	for scanner.Scan() {
-		lastLine = scanner.Text()
+	coverageFile := a.coverageFile()
+	if _, err = os.Stat(coverageFile); err != nil {
+		return 0, missingCoverageFileError(coverageFile)
 	}
 
-	if err = scanner.Err(); err != nil {
-		return 0, fmt.Errorf("error reading coverage output: %w", err)
+	// Only clean up the files we (re)generated.
+	if a.AutoClean && !a.skipTests {
+		defer func() {
+			err = errors.Join(err, os.Remove(coverageFile))
+		}()
 	}
 
-	if !strings.HasPrefix(lastLine, "total:") {
-		return 0, fmt.Errorf("%w: total line missing", errInvalidFileFormat)
+	if a.profile, err = badge.ReadProfile(coverageFile); err != nil {
+		return 0, err //nolint:wrapcheck // ok
 	}
 
-	// Extract percentage from line like "total:						(statements)		87.4%".
-	parts := strings.Fields(lastLine)
-	if x := len(parts); x != 3 { //nolint:mnd // ok
-		return 0, fmt.Errorf("%w: last line parts count != %d", errInvalidFileFormat, x)
+	return a.profile.Percent(), nil
+}
+
+// coverageFile returns the path of the coverage profile produced by the test
+// command. An explicitly configured file wins, then the -coverprofile flag found
+// in the command itself, then the one in the GOFLAGS environment variable and
+// finally the go test default of coverage.out.
+func (a app) coverageFile() string {
+	if a.CoverageFile != "" {
+		return a.CoverageFile
 	}
 
-	covStr := strings.TrimSuffix(parts[2], "%")
+	goflags := ""
+	if a.getenv != nil {
+		goflags = a.getenv("GOFLAGS")
+	}
 
-	cov, err = strconv.ParseFloat(covStr, 64)
-	if err != nil {
-		return 0, fmt.Errorf("could not parse coverage percentage: %w", err)
+	for _, src := range []string{a.TestCommand, goflags} {
+		if file := findCoverProfile(src); file != "" {
+			return file
+		}
 	}
 
-	return
+	return "coverage.out"
+}
+
+func findCoverProfile(command string) string {
+	matches := coverProfileRe.FindStringSubmatch(command)
+	if len(matches) < 2 { //nolint:mnd // ok
+		return ""
+	}
+
+	return strings.Trim(matches[1], `'"`)
+}
+
+// missingCoverageFileError builds an error listing the coverage-looking files
+// found next to the expected one, to help pinpointing a misconfiguration.
+func missingCoverageFileError(coverageFile string) error {
+	dir := filepath.Dir(coverageFile)
+	candidates := []string{}
+
+	for _, pattern := range []string{"*.out", "*.cov"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern)) //nolint:errcheck // only fails on bad patterns
+		candidates = append(candidates, matches...)
+	}
+
+	if len(candidates) == 0 {
+		return fmt.Errorf("%w: %s (no *.out or *.cov files in %s; set coverageFile or -coverage-file)",
+			errCoverageFileMissing, coverageFile, dir)
+	}
+
+	return fmt.Errorf("%w: %s (candidates: %s; set coverageFile or -coverage-file)",
+		errCoverageFileMissing, coverageFile, strings.Join(candidates, ", "))
 }
 
 func (a app) generateBadge() (string, error) {
-	color := a.Levels.GetColorForCoverage(*a.CoveragePC)
-	textColor := getOptimalTextColor(color)
+	svg, err := badge.Render(a.badgeOptions())
+
+	return string(svg), err //nolint:wrapcheck // ok
+}
 
-	data := struct {
-		Coverage  string
-		Color     string
-		TextColor string
-	}{
-		Coverage:  fmt.Sprintf("%.1f", *a.CoveragePC),
-		Color:     color,
-		TextColor: textColor,
+// badgeOptions returns the badge rendering options of the current coverage
+// (or tests results).
+func (a app) badgeOptions() badge.Options {
+	opts := badge.Options{
+		Time:       time.Now(),
+		Tests:      a.tests,
+		Git:        badge.ReadGitInfo("."),
+		Levels:     a.Levels,
+		DarkLevels: a.DarkLevels,
+		Template:   a.Template,
+		Type:       a.BadgeType,
+		ColorMode:  a.ColorMode,
 	}
 
-	tmpl, err := template.New("badge").Parse(a.Template)
-	if err != nil {
-		return "", fmt.Errorf("error parsing template: %w", err)
+	if a.CoveragePC != nil {
+		opts.Coverage = *a.CoveragePC
 	}
 
-	var buf bytes.Buffer
-	if err = tmpl.Execute(&buf, data); err != nil {
-		return "", fmt.Errorf("error executing template: %w", err)
+	if a.profile != nil {
+		opts.Covered, opts.Statements = a.profile.Statements()
 	}
 
-	return buf.String(), nil
+	return opts
 }
 
 func (a app) writeBadgeFile(content string) error {
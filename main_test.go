@@ -1,251 +1,17 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
-)
-
-func TestConfigMerge(t *testing.T) {
-	t.Parallel()
 
-	tests := []struct {
-		name      string
-		base      *config
-		other     *config
-		expected  *config
-		wantError bool
-	}{
-		{
-			name:     "Empty configs",
-			base:     &config{},
-			other:    &config{},
-			expected: &config{},
-		},
-		{
-			name: "Merge non-empty into empty",
-			base: &config{},
-			other: &config{
-				TestCommand: "go test",
-				OutputFile:  "badge.svg",
-				Quiet:       true,
-			},
-			expected: &config{
-				TestCommand: "go test",
-				OutputFile:  "badge.svg",
-				Quiet:       true,
-			},
-		},
-		{
-			name: "Merge into existing config",
-			base: &config{
-				TestCommand:  "original",
-				OutputFile:   "original.svg",
-				Quiet:        false,
-				DumpTemplate: false,
-			},
-			other: &config{
-				TestCommand: "go test",
-				Quiet:       true,
-			},
-			expected: &config{
-				TestCommand:  "go test",
-				OutputFile:   "", // JSON marshal/unmarshal overwrites with zero value
-				Quiet:        true,
-				DumpTemplate: false, // JSON marshal/unmarshal overwrites with zero value
-			},
-		},
-		{
-			name: "Merge levels",
-			base: &config{
-				Levels: Levels{90.0: "#00ff00"},
-			},
-			other: &config{
-				Levels: Levels{70.0: "#ffff00", 0.0: "#ff0000"},
-			},
-			expected: &config{
-				Levels: Levels{70.0: "#ffff00", 0.0: "#ff0000"},
-			},
-		},
-		{
-			name: "JSON roundtrip test",
-			base: &config{
-				TestCommand:  "go test",
-				OutputFile:   "badge.svg",
-				Quiet:        true,
-				AutoClean:    false,
-				DumpTemplate: true,
-				Levels:       Levels{90.0: "#00ff00", 0.0: "#ff0000"},
-			},
-			other: &config{
-				TestCommand: "new command",
-				Quiet:       false,
-				AutoClean:   true,
-			},
-			expected: &config{
-				TestCommand:  "new command",
-				OutputFile:   "", // JSON marshal/unmarshal overwrites with zero value
-				Quiet:        false,
-				AutoClean:    true,
-				DumpTemplate: false,    // JSON marshal/unmarshal overwrites with zero value
-				Levels:       Levels{}, // JSON marshal/unmarshal overwrites with zero value
-			},
-		},
-		{
-			name: "Merge with CoveragePC set",
-			base: &config{
-				TestCommand: "original",
-				OutputFile:  "original.svg",
-			},
-			other: &config{
-				TestCommand: "new command",
-				CoveragePC:  func() *float64 { v := 85.5; return &v }(),
-			},
-			expected: &config{
-				TestCommand: "new command",
-				OutputFile:  "", // JSON marshal/unmarshal overwrites with zero value
-				CoveragePC:  func() *float64 { v := 85.5; return &v }(),
-			},
-		},
-		{
-			name: "Merge with nil CoveragePC",
-			base: &config{
-				TestCommand: "original",
-				CoveragePC:  func() *float64 { v := 90.0; return &v }(),
-			},
-			other: &config{
-				TestCommand: "new command",
-				CoveragePC:  nil,
-			},
-			expected: &config{
-				TestCommand: "new command",
-				OutputFile:  "",                                         // JSON marshal/unmarshal overwrites with zero value
-				CoveragePC:  func() *float64 { v := 90.0; return &v }(), // Should keep base value since other is nil
-			},
-		},
-		{
-			name: "Merge overwriting CoveragePC",
-			base: &config{
-				TestCommand: "original",
-				CoveragePC:  func() *float64 { v := 90.0; return &v }(),
-			},
-			other: &config{
-				TestCommand: "new command",
-				CoveragePC:  func() *float64 { v := 75.2; return &v }(),
-			},
-			expected: &config{
-				TestCommand: "new command",
-				OutputFile:  "", // JSON marshal/unmarshal overwrites with zero value
-				CoveragePC:  func() *float64 { v := 75.2; return &v }(),
-			},
-		},
-		{
-			name: "Merge with invalid levels causing unmarshal error",
-			base: &config{
-				TestCommand: "original",
-			},
-			other: &config{
-				TestCommand: "new command",
-				Levels:      Levels{}, // This will be modified to cause unmarshal error
-			},
-			wantError: false, // Even invalid levels won't cause JSON marshal/unmarshal to fail
-			expected: &config{
-				TestCommand: "new command",
-				OutputFile:  "",
-				Levels:      Levels{},
-			},
-		},
-		{
-			name: "Merge with complex levels",
-			base: &config{
-				TestCommand: "original",
-				Levels:      Levels{50.0: "#yellow"},
-			},
-			other: &config{
-				TestCommand: "new command",
-				Levels:      Levels{90.0: "#00ff00", 70.0: "#ffff00", 0.0: "#ff0000"},
-			},
-			expected: &config{
-				TestCommand: "new command",
-				OutputFile:  "",
-				Levels:      Levels{90.0: "#00ff00", 70.0: "#ffff00", 0.0: "#ff0000"},
-			},
-		},
-		{
-			name: "Merge preserving base CoveragePC when other is nil",
-			base: &config{
-				TestCommand: "original",
-				CoveragePC:  func() *float64 { v := 88.8; return &v }(),
-				Quiet:       true,
-			},
-			other: &config{
-				TestCommand: "updated command",
-				Quiet:       false,
-				CoveragePC:  nil, // Explicitly nil
-			},
-			expected: &config{
-				TestCommand: "updated command",
-				OutputFile:  "",
-				Quiet:       false,
-				CoveragePC:  func() *float64 { v := 88.8; return &v }(), // Should preserve base value
-			},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Parallel()
-
-			err := tt.base.merge(tt.other)
-			if tt.wantError && err == nil {
-				t.Error("Expected error but got none")
-				return
-			}
-
-			if !tt.wantError && err != nil {
-				t.Errorf("Unexpected error: %v", err)
-				return
-			}
-
-			if tt.expected != nil { //nolint:nestif // ok
-				if tt.base.TestCommand != tt.expected.TestCommand {
-					t.Errorf("TestCommand = %q, want %q", tt.base.TestCommand, tt.expected.TestCommand)
-				}
-
-				if tt.base.OutputFile != tt.expected.OutputFile {
-					t.Errorf("OutputFile = %q, want %q", tt.base.OutputFile, tt.expected.OutputFile)
-				}
-
-				if tt.base.Quiet != tt.expected.Quiet {
-					t.Errorf("Quiet = %v, want %v", tt.base.Quiet, tt.expected.Quiet)
-				}
-
-				if tt.base.AutoClean != tt.expected.AutoClean {
-					t.Errorf("AutoClean = %v, want %v", tt.base.AutoClean, tt.expected.AutoClean)
-				}
-
-				if tt.base.DumpTemplate != tt.expected.DumpTemplate {
-					t.Errorf("DumpTemplate = %v, want %v", tt.base.DumpTemplate, tt.expected.DumpTemplate)
-				}
-
-				if len(tt.expected.Levels) > 0 && !tt.base.Levels.eq(tt.expected.Levels) {
-					t.Errorf("Levels = %v, want %v", tt.base.Levels, tt.expected.Levels)
-				}
-
-				if tt.expected.CoveragePC != nil {
-					if tt.base.CoveragePC == nil {
-						t.Error("Expected CoveragePC to be set but it was nil")
-					} else if *tt.base.CoveragePC != *tt.expected.CoveragePC {
-						t.Errorf("CoveragePC = %v, want %v", *tt.base.CoveragePC, *tt.expected.CoveragePC)
-					}
-				}
-			}
-		})
-	}
-}
+	"github.com/alexaandru/stampli/badge"
+)
 
 func TestLoadConfig(t *testing.T) {
 	t.Parallel()
@@ -386,7 +152,7 @@ func TestLoadConfig(t *testing.T) {
 			}
 
 			if !tt.expectError {
-				cfg := &a.config
+				cfg := &a.Config
 
 				if tt.expectedCmd != "" && cfg.TestCommand != tt.expectedCmd {
 					t.Errorf("TestCommand = %q, want %q", cfg.TestCommand, tt.expectedCmd)
@@ -404,6 +170,261 @@ func TestLoadConfig(t *testing.T) {
 	}
 }
 
+func TestLoadConfigLayering(t *testing.T) {
+	t.Parallel()
+
+	configFile := filepath.Join(t.TempDir(), "stampli.yaml")
+
+	err := os.WriteFile(configFile, []byte("outputFile: file.svg\nquiet: true\nminCoverage: 50\ntemplate: file.tmpl\n"), 0o600)
+	if err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	env := map[string]string{
+		"STAMPLI_CONFIG":   configFile,
+		"STAMPLI_TEMPLATE": "env.tmpl",
+		"STAMPLI_MIN":      "60",
+		"STAMPLI_LEVELS":   "80=#00ff00,=#ff0000",
+	}
+
+	a := app{defaultConfig: badge.DefaultConfig(), defaultConfigFile: defaultConfigFile, getenv: func(k string) string { return env[k] }}
+
+	err = a.loadConfig(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-min", "70", "-dump-format", "effective"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if a.ConfigFile != configFile {
+		t.Errorf("ConfigFile = %q, want %q", a.ConfigFile, configFile)
+	}
+
+	values := []struct{ got, want any }{
+		{a.TestCommand, "go test ./... -coverprofile=coverage.out"},         // Default.
+		{a.OutputFile, filepath.Join(filepath.Dir(configFile), "file.svg")}, // Config file, not overridden by the -output flag default.
+		{a.Quiet, true},          // Config file, not overridden by the -quiet flag default.
+		{a.Template, "env.tmpl"}, // Env overrides the config file.
+		{a.Levels.String(), "0=#ff0000,80=#00ff00"},
+		{a.MinCoverage, 70.0}, // Flag overrides env and config file.
+	}
+
+	for i, v := range values {
+		if v.got != v.want {
+			t.Errorf("Value %d = %v, want %v", i, v.got, v.want)
+		}
+	}
+
+	var output strings.Builder
+
+	a.dumpSink = &output
+	if err = a.run(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Ignore the column alignment.
+	got := strings.Join(strings.Fields(output.String()), " ")
+
+	for _, want := range []string{
+		`testCommand "go test ./... -coverprofile=coverage.out" default`,
+		`outputFile "` + filepath.Join(filepath.Dir(configFile), "file.svg") + `" config file ` + configFile,
+		`template "env.tmpl" env STAMPLI_TEMPLATE`,
+		`levels "0=#ff0000,80=#00ff00" env STAMPLI_LEVELS`,
+		`minCoverage 70 flag -min`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Effective config should contain %q, got:\n%s", want, output.String())
+		}
+	}
+}
+
+func TestLoadConfigZeroOverrides(t *testing.T) {
+	t.Parallel()
+
+	configFile := filepath.Join(t.TempDir(), "stampli.json")
+
+	err := os.WriteFile(configFile, []byte(`{"minCoverage": 80, "fileMinCoverage": 70, "gitlab": true, "levels": "80=#00ff00,=#ff0000"}`), 0o600)
+	if err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	env := map[string]string{"STAMPLI_CONFIG": configFile, "STAMPLI_FILE_MIN": "0"}
+	a := app{defaultConfig: badge.DefaultConfig(), defaultConfigFile: defaultConfigFile, getenv: func(k string) string { return env[k] }}
+
+	err = a.loadConfig(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-min", "0", "-gitlab=false", "-levels", "", "-dump-format", "effective"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if a.MinCoverage != 0 || a.FileMinCoverage != 0 || a.GitLab || len(a.Levels) != 0 {
+		t.Errorf("MinCoverage = %v, FileMinCoverage = %v, GitLab = %v, Levels = %q, want the zero overrides",
+			a.MinCoverage, a.FileMinCoverage, a.GitLab, a.Levels.String())
+	}
+
+	var output strings.Builder
+
+	a.dumpSink = &output
+	if err = a.run(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The zero values are listed too, with their source.
+	got := strings.Join(strings.Fields(output.String()), " ")
+
+	for _, want := range []string{`minCoverage 0 flag -min`, `fileMinCoverage 0 env STAMPLI_FILE_MIN`, `gitlab false flag -gitlab`, `levels "" flag -levels`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Effective config should contain %q, got:\n%s", want, output.String())
+		}
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "stampli.yaml")
+	config := "outputFile: coverage.svg\nquiet: true\nminCoverage: 50\nprofiles:\n" +
+		"  unit:\n    outputFile: unit.svg\n    minCoverage: 80\n" +
+		"  e2e:\n    outputFile: e2e.svg\n" +
+		"  off:\n    minCoverage: 0\n    quiet: false\n"
+
+	if err := os.WriteFile(configFile, []byte(config), 0o600); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	newProfileApp := func(t *testing.T, env map[string]string, args ...string) app {
+		t.Helper()
+
+		a := app{defaultConfig: badge.DefaultConfig(), defaultConfigFile: defaultConfigFile, getenv: func(k string) string { return env[k] }}
+
+		err := a.loadConfig(flag.NewFlagSet("test", flag.ContinueOnError), append([]string{"-config", configFile}, args...))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		return a
+	}
+
+	t.Run("Profile overrides the config file", func(t *testing.T) {
+		t.Parallel()
+
+		a := newProfileApp(t, nil, "-profile", "unit")
+		if a.OutputFile != filepath.Join(dir, "unit.svg") || a.MinCoverage != 80 || !a.Quiet {
+			t.Errorf("OutputFile = %q, MinCoverage = %v, Quiet = %v, want the unit profile", a.OutputFile, a.MinCoverage, a.Quiet)
+		}
+
+		if a.sources["outputFile"] != "profile unit" || a.sources["quiet"] != "config file "+configFile {
+			t.Errorf("Sources = %v, want the unit profile ones", a.sources)
+		}
+	})
+
+	t.Run("Profile sets zero values", func(t *testing.T) {
+		t.Parallel()
+
+		a := newProfileApp(t, nil, "-profile", "off")
+		if a.MinCoverage != 0 || a.Quiet {
+			t.Errorf("MinCoverage = %v, Quiet = %v, want the off profile zero values", a.MinCoverage, a.Quiet)
+		}
+
+		if a.sources["minCoverage"] != "profile off" || a.sources["quiet"] != "profile off" {
+			t.Errorf("Sources = %v, want the off profile ones", a.sources)
+		}
+	})
+
+	t.Run("Env and flags override the profile", func(t *testing.T) {
+		t.Parallel()
+
+		a := newProfileApp(t, map[string]string{"STAMPLI_PROFILE": "unit", "STAMPLI_OUTPUT": "env.svg"}, "-min", "90")
+		if a.OutputFile != "env.svg" || a.MinCoverage != 90 {
+			t.Errorf("OutputFile = %q, MinCoverage = %v, want the env and flag values", a.OutputFile, a.MinCoverage)
+		}
+
+		if a.sources["outputFile"] != "env STAMPLI_OUTPUT" || a.sources["minCoverage"] != "flag -min" {
+			t.Errorf("Sources = %v, want the env and flag ones", a.sources)
+		}
+	})
+
+	t.Run("Unknown profile", func(t *testing.T) {
+		t.Parallel()
+
+		a := app{defaultConfig: badge.DefaultConfig(), defaultConfigFile: defaultConfigFile}
+
+		err := a.loadConfig(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-config", configFile, "-profile", "lint"})
+		if !errors.Is(err, badge.ErrUnknownProfile) || !strings.Contains(err.Error(), "available: e2e, off, unit") {
+			t.Errorf("Expected an unknown profile error, got %v", err)
+		}
+	})
+
+	t.Run("All profiles", func(t *testing.T) {
+		t.Parallel()
+
+		a := newProfileApp(t, nil, "-all-profiles", "-coverage", "75")
+
+		err := a.run()
+		if !errors.Is(err, errBelowMinCoverage) || !strings.Contains(err.Error(), "profile unit:") {
+			t.Errorf("Expected the unit profile to fail the min coverage, got %v", err)
+		}
+
+		for _, name := range []string{"unit.svg", "e2e.svg"} {
+			if _, err = os.Stat(filepath.Join(dir, name)); err != nil {
+				t.Errorf("Badge %s not generated: %v", name, err)
+			}
+		}
+	})
+}
+
+func TestLoadConfigInvalidEnv(t *testing.T) {
+	t.Parallel()
+
+	a := app{defaultConfig: badge.DefaultConfig(), getenv: func(k string) string {
+		return map[string]string{"STAMPLI_MIN": "abc"}[k]
+	}}
+
+	err := a.loadConfig(flag.NewFlagSet("test", flag.ContinueOnError), nil)
+	if err == nil || !strings.Contains(err.Error(), "STAMPLI_MIN") {
+		t.Errorf("Expected a STAMPLI_MIN error, got %v", err)
+	}
+
+	// Flags take precedence, the invalid env value is not even parsed.
+	err = a.loadConfig(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-min", "10"})
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestMinCoverage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		coverage float64
+		wantErr  error
+	}{
+		{"Above the minimum", 80, nil},
+		{"At the minimum", 75, nil},
+		{"Below the minimum", 74.9, errBelowMinCoverage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			a := app{}
+			a.CoveragePC = &tt.coverage
+			a.MinCoverage = 75
+			a.Levels = mustLevels(t, "0=#ff0001")
+			a.OutputFile = filepath.Join(t.TempDir(), "badge.svg")
+			a.Quiet = true
+
+			if err := a.run(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("run() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if _, err := os.Stat(a.OutputFile); err != nil {
+				t.Errorf("Badge should be generated regardless of the minimum: %v", err)
+			}
+		})
+	}
+}
+
 func TestRunApplication(t *testing.T) {
 	t.Parallel()
 
@@ -414,14 +435,14 @@ func TestRunApplication(t *testing.T) {
 
 	tests := []struct {
 		name          string
-		setupFunc     func(t *testing.T, tempDir string) *config
+		setupFunc     func(t *testing.T, tempDir string) *badge.Config
 		expectError   bool
 		errorContains string
-		validateFunc  func(t *testing.T, tempDir string, config *config)
+		validateFunc  func(t *testing.T, tempDir string, config *badge.Config)
 	}{
 		{
 			name: "Successful run with valid coverage",
-			setupFunc: func(t *testing.T, tempDir string) *config {
+			setupFunc: func(t *testing.T, tempDir string) *badge.Config {
 				t.Helper()
 
 				coverageFile := filepath.Join(tempDir, "coverage-valid.out")
@@ -437,16 +458,16 @@ func TestRunApplication(t *testing.T) {
 					t.Fatalf("Failed to create coverage file: %v", err)
 				}
 
-				return &config{
+				return &badge.Config{
 					TestCommand:  "echo 'test completed' -coverprofile=" + coverageFile,
 					OutputFile:   filepath.Join(tempDir, "badge.svg"),
-					Levels:       Levels{70.0: "#44cc11", 40.0: "#dfb317", 0.0: "#ff0001"},
+					Levels:       mustLevels(t, "0=#ff0001,40=#dfb317,70=#44cc11"),
 					Template:     "", // Should use default
 					DumpTemplate: false,
 					Quiet:        true,
 				}
 			},
-			validateFunc: func(t *testing.T, tempDir string, config *config) {
+			validateFunc: func(t *testing.T, tempDir string, config *badge.Config) {
 				t.Helper()
 
 				badgeData, err := os.ReadFile(config.OutputFile)
@@ -460,14 +481,14 @@ func TestRunApplication(t *testing.T) {
 					t.Error("Badge doesn't contain SVG content")
 				}
 
-				if !strings.Contains(badgeContent, "97.4") {
+				if !strings.Contains(badgeContent, "93.1") {
 					t.Error("Badge doesn't contain expected coverage percentage")
 				}
 			},
 		},
 		{
 			name: "Partial coverage scenario",
-			setupFunc: func(t *testing.T, tempDir string) *config {
+			setupFunc: func(t *testing.T, tempDir string) *badge.Config {
 				t.Helper()
 
 				coverageFile := filepath.Join(tempDir, "partial-test.out")
@@ -484,17 +505,17 @@ func TestRunApplication(t *testing.T) {
 					t.Fatalf("Failed to create coverage file: %v", err)
 				}
 
-				return &config{
+				return &badge.Config{
 					TestCommand:  "echo 'test' -coverprofile=" + coverageFile,
 					OutputFile:   filepath.Join(tempDir, "badge.svg"),
-					Levels:       Levels{70.0: "#44cc11", 40.0: "#dfb317", 0.0: "#ff0001"},
+					Levels:       mustLevels(t, "0=#ff0001,40=#dfb317,70=#44cc11"),
 					Template:     "", // Use default
 					DumpTemplate: false,
 					Quiet:        true,
 					AutoClean:    false,
 				}
 			},
-			validateFunc: func(t *testing.T, tempDir string, config *config) {
+			validateFunc: func(t *testing.T, tempDir string, config *badge.Config) {
 				t.Helper()
 
 				badgeData, err := os.ReadFile(config.OutputFile)
@@ -511,13 +532,13 @@ func TestRunApplication(t *testing.T) {
 		},
 		{
 			name: "Invalid command",
-			setupFunc: func(t *testing.T, tempDir string) *config {
+			setupFunc: func(t *testing.T, tempDir string) *badge.Config {
 				t.Helper()
 
-				return &config{
+				return &badge.Config{
 					TestCommand: "nonexistent-command-12345",
 					OutputFile:  filepath.Join(tempDir, "badge.svg"),
-					Levels:      Levels{70.0: "#44cc11", 40.0: "#dfb317", 0.0: "#ff0001"},
+					Levels:      mustLevels(t, "0=#ff0001,40=#dfb317,70=#44cc11"),
 				}
 			},
 			expectError:   true,
@@ -525,13 +546,13 @@ func TestRunApplication(t *testing.T) {
 		},
 		{
 			name: "Empty command",
-			setupFunc: func(t *testing.T, tempDir string) *config {
+			setupFunc: func(t *testing.T, tempDir string) *badge.Config {
 				t.Helper()
 
-				return &config{
+				return &badge.Config{
 					TestCommand: "",
 					OutputFile:  filepath.Join(tempDir, "badge.svg"),
-					Levels:      Levels{70.0: "#44cc11"},
+					Levels:      mustLevels(t, "0=#ff0001,70=#44cc11"),
 				}
 			},
 			expectError:   true,
@@ -539,7 +560,7 @@ func TestRunApplication(t *testing.T) {
 		},
 		{
 			name: "Invalid output directory",
-			setupFunc: func(t *testing.T, tempDir string) *config {
+			setupFunc: func(t *testing.T, tempDir string) *badge.Config {
 				t.Helper()
 
 				coverageFile := filepath.Join(tempDir, "invalid-dir-test.out")
@@ -556,32 +577,32 @@ func TestRunApplication(t *testing.T) {
 					t.Fatalf("Failed to create coverage file: %v", err)
 				}
 
-				return &config{
+				return &badge.Config{
 					TestCommand: "echo 'test' -coverprofile=" + coverageFile,
 					OutputFile:  "/nonexistent/directory/badge.svg",
-					Levels:      Levels{70.0: "#44cc11"},
+					Levels:      mustLevels(t, "0=#ff0001,70=#44cc11"),
 					Quiet:       true,
 				}
 			},
 			expectError:   true,
-			errorContains: "error writing badge file",
+			errorContains: "invalid output file /nonexistent/directory/badge.svg",
 		},
 
 		{
 			name: "Run with direct coverage percentage",
-			setupFunc: func(t *testing.T, tempDir string) *config {
+			setupFunc: func(t *testing.T, tempDir string) *badge.Config {
 				t.Helper()
 
 				coverage := 82.5
 
-				return &config{
+				return &badge.Config{
 					CoveragePC: &coverage,
 					OutputFile: filepath.Join(tempDir, "badge.svg"),
-					Levels:     Levels{70.0: "#44cc11", 40.0: "#dfb317", 0.0: "#ff0001"},
+					Levels:     mustLevels(t, "0=#ff0001,40=#dfb317,70=#44cc11"),
 					Template:   "", // Use default
 				}
 			},
-			validateFunc: func(t *testing.T, tempDir string, config *config) {
+			validateFunc: func(t *testing.T, tempDir string, config *badge.Config) {
 				t.Helper()
 
 				badgeData, err := os.ReadFile(config.OutputFile)
@@ -602,20 +623,20 @@ func TestRunApplication(t *testing.T) {
 		},
 		{
 			name: "Template loading error",
-			setupFunc: func(t *testing.T, tempDir string) *config {
+			setupFunc: func(t *testing.T, tempDir string) *badge.Config {
 				t.Helper()
 
 				coverage := 75.0
 
-				return &config{
+				return &badge.Config{
 					CoveragePC: &coverage,
 					OutputFile: filepath.Join(tempDir, "badge.svg"),
 					Template:   "/nonexistent/template.svg",
-					Levels:     Levels{70.0: "#44cc11"},
+					Levels:     mustLevels(t, "0=#ff0001,70=#44cc11"),
 				}
 			},
 			expectError:   true,
-			errorContains: "could not read template file",
+			errorContains: "unreadable template file",
 		},
 	}
 
@@ -625,7 +646,7 @@ func TestRunApplication(t *testing.T) {
 
 			tempDir := t.TempDir()
 
-			a := app{config: *tt.setupFunc(t, tempDir)}
+			a := app{Config: *tt.setupFunc(t, tempDir)}
 			err := a.run()
 
 			if tt.expectError && err == nil {
@@ -645,99 +666,7 @@ func TestRunApplication(t *testing.T) {
 			}
 
 			if tt.validateFunc != nil {
-				tt.validateFunc(t, tempDir, &a.config)
-			}
-		})
-	}
-}
-
-func TestLoadTemplate(t *testing.T) {
-	t.Parallel()
-
-	tests := []struct {
-		name             string
-		setupFunc        func(t *testing.T, tempDir string) *config
-		expectError      bool
-		expectedTemplate string
-	}{
-		{
-			name: "Load custom template file",
-			setupFunc: func(t *testing.T, tempDir string) *config {
-				t.Helper()
-
-				testTemplateFile := filepath.Join(tempDir, "test-template.svg")
-				testTemplateContent := `<svg><text>{{.Coverage}}% test</text></svg>`
-
-				err := os.WriteFile(testTemplateFile, []byte(testTemplateContent), 0o644)
-				if err != nil {
-					t.Fatalf("Failed to create test template: %v", err)
-				}
-
-				return &config{Template: testTemplateFile}
-			},
-			expectedTemplate: `<svg><text>{{.Coverage}}% test</text></svg>`,
-		},
-		{
-			name: "Use default template when no custom template",
-			setupFunc: func(t *testing.T, tempDir string) *config {
-				t.Helper()
-
-				return &config{Template: ""}
-			},
-			expectedTemplate: defaultTemplate,
-		},
-		{
-			name: "Error loading non-existent template file",
-			setupFunc: func(t *testing.T, tempDir string) *config {
-				t.Helper()
-
-				return &config{Template: filepath.Join(tempDir, "nonexistent-template.svg")}
-			},
-			expectError: true,
-		},
-		{
-			name: "Load template from directory without read permissions",
-			setupFunc: func(t *testing.T, tempDir string) *config {
-				t.Helper()
-
-				if os.Geteuid() == 0 {
-					t.Skip("Skipping permission test when running as root")
-				}
-
-				restrictedDir := filepath.Join(tempDir, "restricted")
-				err := os.Mkdir(restrictedDir, 0o000)
-				if err != nil {
-					t.Fatalf("Failed to create restricted directory: %v", err)
-				}
-
-				return &config{Template: filepath.Join(restrictedDir, "template.svg")}
-			},
-			expectError: true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Parallel()
-
-			tempDir := t.TempDir()
-			a := app{config: *tt.setupFunc(t, tempDir)}
-
-			err := a.loadTemplate()
-			if tt.expectError && err == nil {
-				t.Error("Expected error but got none")
-				return
-			}
-
-			if !tt.expectError && err != nil {
-				t.Errorf("Unexpected error: %v", err)
-				return
-			}
-
-			if !tt.expectError && tt.expectedTemplate != "" {
-				if a.Template != tt.expectedTemplate {
-					t.Errorf("Template content = %q, want %q", a.Template, tt.expectedTemplate)
-				}
+				tt.validateFunc(t, tempDir, &a.Config)
 			}
 		})
 	}
@@ -795,7 +724,7 @@ func TestRunTestsAndGetCoverage(t *testing.T) {
 
 				return os.WriteFile(coverageFile, data, 0o644)
 			},
-			expectedRange: []float64{95.0, 99.0}, // Using testdata coverage which is ~97%
+			expectedRange: []float64{92.0, 94.0}, // Using testdata coverage which is ~93%
 		},
 		{
 			name:      "Auto clean coverage file",
@@ -833,7 +762,7 @@ func TestRunTestsAndGetCoverage(t *testing.T) {
 
 				return os.WriteFile(coverageFile, data, 0o644)
 			},
-			expectedRange: []float64{95.0, 99.0},
+			expectedRange: []float64{92.0, 94.0},
 		},
 		{
 			name:    "Test with sample coverage file",
@@ -881,7 +810,7 @@ func TestRunTestsAndGetCoverage(t *testing.T) {
 				}
 			}
 
-			a := app{config: config{TestCommand: command, AutoClean: tt.autoClean}}
+			a := app{Config: badge.Config{TestCommand: command, AutoClean: tt.autoClean}}
 
 			coverage, err := a.runTestsAndGetCoverage()
 			if tt.expectError && err == nil {
@@ -901,69 +830,128 @@ func TestRunTestsAndGetCoverage(t *testing.T) {
 				}
 			}
 
-			if tt.autoClean && !tt.expectError {
-				coverageFile := filepath.Join(tempDir, "coverage.out")
-				if _, err := os.Stat(coverageFile); err == nil {
-					t.Error("Expected coverage file to be cleaned up, but it still exists")
-				}
+			if tt.autoClean && !tt.expectError {
+				coverageFile := filepath.Join(tempDir, "coverage.out")
+				if _, err := os.Stat(coverageFile); err == nil {
+					t.Error("Expected coverage file to be cleaned up, but it still exists")
+				}
+			}
+		})
+	}
+}
+
+func TestFindCoverProfile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		command  string
+		expected string
+	}{
+		{"No flag", "go test ./...", ""},
+		{"Equals form", "go test ./... -coverprofile=unit.out", "unit.out"},
+		{"Space separated form", "go test ./... -coverprofile unit.out -race", "unit.out"},
+		{"Double dash", "go test ./... --coverprofile=unit.out", "unit.out"},
+		{"Test binary form", "./pkg.test -test.coverprofile=bin.out", "bin.out"},
+		{"Inline GOFLAGS", "GOFLAGS=-coverprofile=flags.out go test ./...", "flags.out"},
+		{"Quoted GOFLAGS", "GOFLAGS='-coverprofile=quoted.out' go test ./...", "quoted.out"},
+		{"Quoted GOFLAGS words", `GOFLAGS="-count=1 -coverprofile=c.out" go test ./...`, "c.out"},
+		{"Similar flag name", "go test -mycoverprofile=no.out", ""},
+		{"GOFLAGS value only", "-race -coverprofile=env.cov", "env.cov"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := findCoverProfile(tt.command); got != tt.expected {
+				t.Errorf("findCoverProfile(%q) = %q, want %q", tt.command, got, tt.expected)
 			}
 		})
 	}
 }
 
-func TestParseCoverageFile(t *testing.T) {
+func TestCommandEnv(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
-		name        string
-		fileContent string
-		expected    float64
-		shouldError bool
+		command string
+		env     []string
+		args    []string
+		wantErr error
+	}{
+		{command: "go test ./...", args: []string{"go", "test", "./..."}},
+		{command: "GOFLAGS=-coverprofile=flags.out go test", env: []string{"GOFLAGS=-coverprofile=flags.out"}, args: []string{"go", "test"}},
+		{
+			command: `GOFLAGS="-count=1 -coverprofile=c.out" CGO_ENABLED='1' go test ./...`,
+			env:     []string{"GOFLAGS=-count=1 -coverprofile=c.out", "CGO_ENABLED=1"},
+			args:    []string{"go", "test", "./..."},
+		},
+		{command: `go test -run='Test A' "-tags=a b"`, args: []string{"go", "test", "-run=Test A", "-tags=a b"}},
+		{command: `echo a\ b "\"c\" \d" 'e\'`, args: []string{"echo", "a b", `"c" \d`, `e\`}},
+		{command: `echo ""`, args: []string{"echo", ""}},
+		{command: "X=1", env: []string{"X=1"}},
+		{command: `GOFLAGS="-race go test`, wantErr: errUnterminatedQuote},
+		{command: `go test \`, wantErr: errUnterminatedQuote},
+	}
+
+	for _, tt := range tests {
+		words, err := splitWords(tt.command)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("splitWords(%q) error = %v, want %v", tt.command, err, tt.wantErr)
+			continue
+		}
+
+		env, args := commandEnv(words)
+		if !slices.Equal(env, tt.env) || !slices.Equal(args, tt.args) {
+			t.Errorf("commandEnv(%q) = %q, %q, want %q, %q", tt.command, env, args, tt.env, tt.args)
+		}
+	}
+
+	a := app{}
+	a.TestCommand = `STAMPLI_INLINE_ENV="a b" sh -c 'test "$STAMPLI_INLINE_ENV" = "a b"'`
+
+	if err := a.runTests(); err != nil {
+		t.Errorf("Expected the inline variable to be set for the command, got %v", err)
+	}
+
+	a.TestCommand = "STAMPLI_INLINE_ENV=set"
+	if err := a.runTests(); !errors.Is(err, errEmptyCommand) {
+		t.Errorf("Expected error %v, got %v", errEmptyCommand, err)
+	}
+}
+
+func TestCoverageFile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		config   badge.Config
+		goflags  string
+		expected string
 	}{
 		{
-			name:        "Empty coverage file (mode only)",
-			fileContent: `mode: set`,
-			expected:    0.0,
-		},
-		{
-			name:        "Invalid file format - no mode line",
-			fileContent: "invalid content without mode line",
-			shouldError: true,
-		},
-		{
-			name: "Invalid file format - malformed lines",
-			fileContent: `mode: set
-invalid line format
-malformed data`,
-			shouldError: true,
-		},
-		{
-			name:        "Non-existent file",
-			fileContent: "", // This will be handled by creating a temp file
-			shouldError: true,
+			name:     "Default",
+			config:   badge.Config{TestCommand: "make test"},
+			expected: "coverage.out",
 		},
 		{
-			name: "Coverage file with missing total line",
-			fileContent: `mode: set
-github.com/alexaandru/stampli/main.go:55:68,55:73 1 0
-github.com/alexaandru/stampli/main.go:76:2,77:16 1 1`,
-			shouldError: true,
+			name:     "From command",
+			config:   badge.Config{TestCommand: "go test -coverprofile cmd.out ./..."},
+			goflags:  "-coverprofile=env.out",
+			expected: "cmd.out",
 		},
 		{
-			name: "Coverage file with malformed total line - wrong field count",
-			fileContent: `mode: set
-github.com/alexaandru/stampli/main.go:55:68,55:73 1 0
-github.com/alexaandru/stampli/main.go:76:2,77:16 1 1
-total: statements`,
-			shouldError: true,
+			name:     "From GOFLAGS",
+			config:   badge.Config{TestCommand: "make test"},
+			goflags:  "-coverprofile=env.out",
+			expected: "env.out",
 		},
 		{
-			name: "Coverage file with invalid percentage format",
-			fileContent: `mode: set
-github.com/alexaandru/stampli/main.go:55:68,55:73 1 0
-github.com/alexaandru/stampli/main.go:76:2,77:16 1 1
-total:						(statements)		invalid%`,
-			shouldError: true,
+			name:     "Explicit file wins",
+			config:   badge.Config{TestCommand: "go test -coverprofile=cmd.out", CoverageFile: "explicit.out"},
+			goflags:  "-coverprofile=env.out",
+			expected: "explicit.out",
 		},
 	}
 
@@ -971,42 +959,61 @@ total:						(statements)		invalid%`,
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			var (
-				filename string
-				err      error
-			)
+			a := app{Config: tt.config, getenv: func(k string) string { return map[string]string{"GOFLAGS": tt.goflags}[k] }}
+			if got := a.coverageFile(); got != tt.expected {
+				t.Errorf("coverageFile() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
 
-			if tt.name == "Non-existent file" {
-				filename = "nonexistent-file.out"
-			} else {
-				tempDir := t.TempDir()
-				filename = filepath.Join(tempDir, "coverage.out")
+func TestMissingCoverageFile(t *testing.T) {
+	t.Parallel()
 
-				err = os.WriteFile(filename, []byte(tt.fileContent), 0o644)
-				if err != nil {
-					t.Fatalf("Failed to create test file: %v", err)
-				}
-			}
+	t.Run("With candidates", func(t *testing.T) {
+		t.Parallel()
 
-			coverage, err := parseCoverageFile(filename)
+		tempDir := t.TempDir()
 
-			if tt.shouldError && err == nil {
-				t.Error("Expected error but got none")
-				return
+		for _, name := range []string{"unit.cov", "other.out", "notes.txt"} {
+			if err := os.WriteFile(filepath.Join(tempDir, name), []byte("mode: set\n"), 0o644); err != nil {
+				t.Fatalf("Failed to create file: %v", err)
 			}
+		}
 
-			if !tt.shouldError && err != nil {
-				t.Errorf("Unexpected error: %v", err)
-				return
-			}
+		a := app{Config: badge.Config{TestCommand: "true", CoverageFile: filepath.Join(tempDir, "coverage.out")}}
 
-			if !tt.shouldError {
-				if abs(coverage-tt.expected) > 0.1 {
-					t.Errorf("Coverage = %.1f, want %.1f", coverage, tt.expected)
-				}
+		_, err := a.runTestsAndGetCoverage()
+		if !errors.Is(err, errCoverageFileMissing) {
+			t.Fatalf("Expected errCoverageFileMissing, got %v", err)
+		}
+
+		for _, want := range []string{"unit.cov", "other.out"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("Error should list candidate %q, got %q", want, err.Error())
 			}
-		})
-	}
+		}
+
+		if strings.Contains(err.Error(), "notes.txt") {
+			t.Errorf("Error should not list notes.txt, got %q", err.Error())
+		}
+	})
+
+	t.Run("Without candidates", func(t *testing.T) {
+		t.Parallel()
+
+		tempDir := t.TempDir()
+		a := app{Config: badge.Config{TestCommand: "true -coverprofile " + filepath.Join(tempDir, "c.out")}}
+
+		_, err := a.runTestsAndGetCoverage()
+		if !errors.Is(err, errCoverageFileMissing) {
+			t.Fatalf("Expected errCoverageFileMissing, got %v", err)
+		}
+
+		if !strings.Contains(err.Error(), "no *.out or *.cov files") {
+			t.Errorf("Unexpected error message %q", err.Error())
+		}
+	})
 }
 
 func TestGenerateBadge(t *testing.T) {
@@ -1017,88 +1024,88 @@ func TestGenerateBadge(t *testing.T) {
 	tests := []struct {
 		name        string
 		coverage    float64
-		config      *config
+		config      *badge.Config
 		shouldError bool
 		contains    []string
 	}{
 		{
 			name:     "High coverage with green color",
 			coverage: 85.5,
-			config: &config{
+			config: &badge.Config{
 				Template: simpleTemplate,
-				Levels:   Levels{70.0: "#44cc11", 40.0: "#dfb317", 0.0: "#ff0001"},
+				Levels:   mustLevels(t, "0=#ff0001,40=#dfb317,70=#44cc11"),
 			},
 			contains: []string{"85.5", "#44cc11"},
 		},
 		{
 			name:     "Medium coverage with yellow color",
 			coverage: 55.2,
-			config: &config{
+			config: &badge.Config{
 				Template: simpleTemplate,
-				Levels:   Levels{70.0: "#44cc11", 40.0: "#dfb317", 0.0: "#ff0001"},
+				Levels:   mustLevels(t, "0=#ff0001,40=#dfb317,70=#44cc11"),
 			},
 			contains: []string{"55.2", "#dfb317"},
 		},
 		{
 			name:     "Low coverage with red color",
 			coverage: 25.0,
-			config: &config{
+			config: &badge.Config{
 				Template: simpleTemplate,
-				Levels:   Levels{70.0: "#44cc11", 40.0: "#dfb317", 0.0: "#ff0001"},
+				Levels:   mustLevels(t, "0=#ff0001,40=#dfb317,70=#44cc11"),
 			},
 			contains: []string{"25.0", "#ff0001"},
 		},
 		{
 			name:     "Zero coverage",
 			coverage: 0.0,
-			config: &config{
+			config: &badge.Config{
 				Template: simpleTemplate,
-				Levels:   Levels{70.0: "#44cc11", 40.0: "#dfb317", 0.0: "#ff0001"},
+				Levels:   mustLevels(t, "0=#ff0001,40=#dfb317,70=#44cc11"),
 			},
 			contains: []string{"0.0", "#ff0001"},
 		},
 		{
 			name:     "Perfect coverage",
 			coverage: 100.0,
-			config: &config{
+			config: &badge.Config{
 				Template: simpleTemplate,
-				Levels:   Levels{70.0: "#44cc11", 40.0: "#dfb317", 0.0: "#ff0001"},
+				Levels:   mustLevels(t, "0=#ff0001,40=#dfb317,70=#44cc11"),
 			},
 			contains: []string{"100.0", "#44cc11"},
 		},
 		{
 			name:     "Custom template with all variables",
 			coverage: 75.0,
-			config: &config{
+			config: &badge.Config{
 				Template: `<svg><text fill="{{.TextColor}}">{{.Coverage}}%</text><rect fill="{{.Color}}"/></svg>`,
-				Levels:   Levels{70.0: "#44cc11", 40.0: "#dfb317", 0.0: "#ff0001"},
+				Levels:   mustLevels(t, "0=#ff0001,40=#dfb317,70=#44cc11"),
 			},
 			contains: []string{"75.0", "#44cc11"},
 		},
 		{
 			name:     "Default template test",
 			coverage: 80.0,
-			config: &config{
-				Template: defaultTemplate,
-				Levels:   Levels{70.0: "#44cc11", 40.0: "#dfb317", 0.0: "#ff0001"},
+			config: &badge.Config{
+				Template: badge.DefaultTemplate(""),
+				Levels:   mustLevels(t, "0=#ff0001,40=#dfb317,70=#44cc11"),
 			},
 			contains: []string{"80.0"},
 		},
 		{
 			name:     "Template with invalid syntax",
 			coverage: 50.0,
-			config: &config{
+			config: &badge.Config{
 				Template: `<svg><text>{{.Coverage}%</text></svg>`, // Missing closing brace
-				Levels:   Levels{70.0: "#44cc11"},
+				Levels:   mustLevels(t, "0=#ff0001,70=#44cc11"),
 			},
 			shouldError: true,
 		},
 		{
 			name:     "Template with undefined variable",
 			coverage: 60.0,
-			config: &config{
+			config: &badge.Config{
 				Template: `<svg><text>{{.UndefinedVar}}%</text></svg>`,
-				Levels:   Levels{70.0: "#44cc11"},
+				Levels:   mustLevels(t, "0=#ff0001,70=#44cc11"),
 			},
 			shouldError: true,
 		},
@@ -1108,7 +1115,7 @@ func TestGenerateBadge(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			a := app{config: *tt.config}
+			a := app{Config: *tt.config}
 			a.CoveragePC = &tt.coverage
 
 			result, err := a.generateBadge()
@@ -1133,6 +1140,128 @@ func TestGenerateBadge(t *testing.T) {
 	}
 }
 
+func TestGenerateBadgeExtendedData(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+	output := filepath.Join(tempDir, "badge.json")
+	a := app{Config: badge.Config{
+		TestCommand: "echo -coverprofile=testdata/coverage-sample.out",
+		OutputFile:  output,
+		Template:    filepath.Join(tempDir, "badge.tmpl"),
+		Levels:      mustLevels(t, "0=#ff0001"),
+		Quiet:       true,
+	}}
+
+	tmpl := `{{.Covered}}/{{.Statements}} {{.Time.IsZero}}`
+	if err := os.WriteFile(a.Template, []byte(tmpl), 0o644); err != nil {
+		t.Fatalf("Failed to create template: %v", err)
+	}
+
+	if err := a.run(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Badge file not created: %v", err)
+	}
+
+	if want := "54/58 false"; string(data) != want {
+		t.Errorf("Badge = %q, want %q", data, want)
+	}
+}
+
+func TestTestsBadge(t *testing.T) {
+	t.Parallel()
+
+	levels := mustLevels(t, "0=#ff0001,70=#44cc11")
+
+	tests := []struct {
+		name          string
+		command       string
+		expectError   bool
+		errorContains string
+		contains      []string
+	}{
+		{
+			name:     "Passing tests",
+			command:  "cat testdata/test-events-pass.json",
+			contains: []string{"tests: 3 passed, 1 skipped", "#44cc11"},
+		},
+		{
+			name:          "Failing tests",
+			command:       "cat testdata/test-events-fail.json",
+			expectError:   true,
+			errorContains: "failing tests",
+			contains:      []string{"tests: failing", "#ff0001"},
+		},
+		{
+			name:          "Command without -json output",
+			command:       "echo ok",
+			expectError:   true,
+			errorContains: "no go test -json events",
+		},
+		{
+			name:          "Failing command without -json output",
+			command:       "false",
+			expectError:   true,
+			errorContains: "test command failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			output := filepath.Join(t.TempDir(), "tests.svg")
+			a := app{Config: badge.Config{
+				TestCommand: tt.command,
+				BadgeType:   badge.TypeTests,
+				OutputFile:  output,
+				Levels:      levels,
+				Quiet:       true,
+			}}
+
+			err := a.run()
+			if tt.expectError {
+				if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("Expected error containing %q, got %v", tt.errorContains, err)
+				}
+			} else if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			// The badge is written for failing tests too.
+			if len(tt.contains) == 0 {
+				return
+			}
+
+			data, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatalf("Badge file not created: %v", err)
+			}
+
+			for _, want := range tt.contains {
+				if !strings.Contains(string(data), want) {
+					t.Errorf("Badge should contain %q, got: %s", want, data)
+				}
+			}
+		})
+	}
+}
+
+func TestUnknownBadgeType(t *testing.T) {
+	t.Parallel()
+
+	coverage := 50.0
+	a := app{Config: badge.Config{BadgeType: "nope", CoveragePC: &coverage}}
+
+	if err := a.run(); !errors.Is(err, badge.ErrUnknownType) {
+		t.Errorf("Expected badge.ErrUnknownType, got %v", err)
+	}
+}
+
 func TestWriteBadgeFile(t *testing.T) {
 	t.Parallel()
 
@@ -1278,7 +1407,7 @@ func TestWriteBadgeFile(t *testing.T) {
 
 			tempDir := t.TempDir()
 			filename, content := tt.setupFunc(t, tempDir)
-			a := app{config: config{OutputFile: filename}}
+			a := app{Config: badge.Config{OutputFile: filename}}
 
 			err := a.writeBadgeFile(content)
 			if tt.expectError && err == nil {
@@ -1298,29 +1427,6 @@ func TestWriteBadgeFile(t *testing.T) {
 	}
 }
 
-// Helper function for floating point comparison
-func abs(x float64) float64 {
-	if x < 0 {
-		return -x
-	}
-
-	return x
-}
-
-func (l *Levels) eq(other Levels) bool {
-	if len(*l) != len(other) {
-		return false
-	}
-
-	for level, color := range *l {
-		if other[level] != color {
-			return false
-		}
-	}
-
-	return true
-}
-
 func TestNewApp(t *testing.T) {
 	t.Parallel()
 
@@ -1377,6 +1483,33 @@ func TestNewApp(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "Validate command",
+			args: []string{"validate", "-quiet"},
+			validate: func(t *testing.T, app app) {
+				t.Helper()
+
+				if app.command.name != "validate" || !app.Quiet {
+					t.Errorf("command = %q, Quiet = %v, want validate, true", app.command.name, app.Quiet)
+				}
+			},
+		},
+		{
+			name: "Dump config format",
+			args: []string{"-dump-format", "toml"},
+			validate: func(t *testing.T, app app) {
+				t.Helper()
+
+				if app.dumpFormat != badge.FormatTOML {
+					t.Errorf("dumpFormat = %q, want %q", app.dumpFormat, badge.FormatTOML)
+				}
+			},
+		},
+		{
+			name:        "Invalid dump config format",
+			args:        []string{"-dump-format", "xml"},
+			expectError: true,
+		},
 		{
 			name:        "Invalid flag",
 			args:        []string{"-invalid-flag"},
@@ -1417,24 +1550,52 @@ func TestAppDumpOperations(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
-		name     string
-		config   config
-		contains string
+		name       string
+		config     badge.Config
+		dumpFormat string
+		dumpSchema bool
+		contains   string
 	}{
 		{
 			name: "Dump template",
-			config: config{
+			config: badge.Config{
 				DumpTemplate: true,
 			},
 			contains: "<svg",
 		},
+		{
+			name: "Dump tests template",
+			config: badge.Config{
+				DumpTemplate: true,
+				BadgeType:    badge.TypeTests,
+			},
+			contains: "{{.Value}}",
+		},
 		{
 			name: "Dump config",
-			config: config{
+			config: badge.Config{
 				DumpConfig: true,
 			},
 			contains: "testCommand",
 		},
+		{
+			name: "Dump YAML config",
+			config: badge.Config{
+				DumpConfig: true,
+			},
+			dumpFormat: badge.FormatYAML,
+			contains:   "testCommand: go test",
+		},
+		{
+			name:       "Dump TOML config, without -dump-config",
+			dumpFormat: badge.FormatTOML,
+			contains:   `testCommand = "go test`,
+		},
+		{
+			name:       "Dump schema",
+			dumpSchema: true,
+			contains:   `"unevaluatedProperties": false`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -1444,8 +1605,10 @@ func TestAppDumpOperations(t *testing.T) {
 			var output strings.Builder
 
 			a := app{
-				config:   tt.config,
-				dumpSink: &output,
+				Config:     tt.config,
+				dumpSink:   &output,
+				dumpFormat: tt.dumpFormat,
+				dumpSchema: tt.dumpSchema,
 			}
 
 			err := a.run()
@@ -1461,3 +1624,172 @@ func TestAppDumpOperations(t *testing.T) {
 		})
 	}
 }
+
+func TestFindConfigFile(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+	json, yml := filepath.Join(tempDir, "stampli.json"), filepath.Join(tempDir, "stampli.yml")
+
+	if got, ok := findConfigFile(json); ok {
+		t.Errorf("findConfigFile() = %q, want none when no config exists", got)
+	}
+
+	if err := os.WriteFile(yml, []byte("quiet: true\n"), 0o600); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	if got, _ := findConfigFile(json); got != yml {
+		t.Errorf("findConfigFile() = %q, want %q", got, yml)
+	}
+
+	if err := os.WriteFile(json, []byte("{}"), 0o600); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	if got, _ := findConfigFile(json); got != json {
+		t.Errorf("findConfigFile() = %q, want %q, JSON taking precedence", got, json)
+	}
+}
+
+func TestDiscoverConfigFiles(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	pkg := filepath.Join(repo, "internal", "pkg")
+	xdg := filepath.Join(root, "xdg")
+
+	files := map[string]string{
+		filepath.Join(root, "stampli.json"):           `{"quiet": true}`, // Above the repository root, ignored.
+		filepath.Join(repo, "go.mod"):                 "module example.com/repo\n",
+		filepath.Join(repo, "stampli.yaml"):           "outputFile: docs/badge.svg\ntemplate: badge.tmpl\n",
+		filepath.Join(xdg, "stampli", "stampli.toml"): "outputFile = \"user.svg\"\nminCoverage = 50.0\n",
+	}
+
+	for filename, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filename), 0o750); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+
+		if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	if err := os.MkdirAll(pkg, 0o750); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		start    string
+		xdg      string
+		expected []string
+	}{
+		{"From a subpackage", pkg, "", []string{filepath.Join(repo, "stampli.yaml")}},
+		{"From the repository root", repo, "", []string{filepath.Join(repo, "stampli.yaml")}},
+		{"With user defaults", pkg, xdg, []string{filepath.Join(xdg, "stampli", "stampli.toml"), filepath.Join(repo, "stampli.yaml")}},
+		{"User defaults only", filepath.Join(xdg, "stampli", "sub"), xdg, []string{filepath.Join(xdg, "stampli", "stampli.toml")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			a := app{
+				defaultConfigFile: filepath.Join(tt.start, defaultConfigFile),
+				getenv:            func(k string) string { return map[string]string{"XDG_CONFIG_HOME": tt.xdg}[k] },
+			}
+
+			if got := a.discoverConfigFiles(); !slices.Equal(got, tt.expected) {
+				t.Errorf("discoverConfigFiles() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+
+	a := app{
+		defaultConfig:     badge.DefaultConfig(),
+		defaultConfigFile: filepath.Join(pkg, defaultConfigFile),
+		getenv:            func(k string) string { return map[string]string{"XDG_CONFIG_HOME": xdg}[k] },
+	}
+
+	if err := a.loadConfig(flag.NewFlagSet("test", flag.ContinueOnError), nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Relative paths are resolved against the directory of the config defining them.
+	if want := filepath.Join(repo, "docs", "badge.svg"); a.OutputFile != want {
+		t.Errorf("OutputFile = %q, want %q", a.OutputFile, want)
+	}
+
+	if want := filepath.Join(repo, "badge.tmpl"); a.Template != want {
+		t.Errorf("Template = %q, want %q", a.Template, want)
+	}
+
+	if a.MinCoverage != 50 || a.Quiet {
+		t.Errorf("MinCoverage = %v, Quiet = %v, want the user defaults only", a.MinCoverage, a.Quiet)
+	}
+
+	if want := filepath.Join(repo, "stampli.yaml"); a.ConfigFile != want || a.sources["outputFile"] != "config file "+want {
+		t.Errorf("ConfigFile = %q, outputFile source = %q, want %q", a.ConfigFile, a.sources["outputFile"], want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		output     string
+		levels     string
+		darkLevels string
+		expected   string
+		wantErr    error
+	}{
+		{"Readable levels", "badge.svg", "85=#44cc11/#000,=#b00020", "", "Configuration is valid\n", nil},
+		{"Empty output file", "", "85=#44cc11/#000,=#b00020", "", "", badge.ErrEmptyOutput},
+		{"Default levels", "badge.svg", "85=#44cc11,70=#dfb317,50=#ff8c00,=#ff0001", "", "Configuration is valid\n", nil},
+		{
+			"Low contrast levels", "badge.svg", "85=#44cc11/#fff,=#b00020", "=#333//#ccc",
+			"level 85: text #fff on #44cc11 has a 2.12:1 contrast ratio (minimum 4.5:1), " +
+				"try text color #000000 or level color #2d870b\n" +
+				"dark level 0: label #fff on #ccc has a 1.61:1 contrast ratio (minimum 4.5:1), try label color #767676\n",
+			errLowContrast,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var output strings.Builder
+
+			a := app{command: commandNamed(t, "validate"), dumpSink: &output}
+			a.Levels = mustLevels(t, tt.levels)
+			a.DarkLevels = mustLevels(t, tt.darkLevels)
+
+			if tt.output != "" {
+				a.OutputFile = filepath.Join(t.TempDir(), tt.output)
+			}
+
+			if err := a.run(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("run() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if output.String() != tt.expected {
+				t.Errorf("Output = %q, want %q", output.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func mustLevels(t *testing.T, value string) (levels badge.Levels) {
+	t.Helper()
+
+	if err := levels.Set(value); err != nil {
+		t.Fatalf("Invalid levels %q: %v", value, err)
+	}
+
+	return
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/alexaandru/stampli/badge"
+)
+
+// writeMarkdown writes the Markdown coverage report to MarkdownFile, if set.
+// Posting it (i.e. as a PR comment) is left to the CI tooling.
+func (a app) writeMarkdown() error {
+	if a.MarkdownFile == "" || a.BadgeType == badge.TypeTests {
+		return nil
+	}
+
+	var baseline *badge.Profile
+
+	if a.BaselineFile != "" {
+		var err error
+
+		// A missing baseline (i.e. the first run on the target branch) is not an error.
+		if baseline, err = badge.ReadProfile(a.BaselineFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("error reading the baseline: %w", err)
+		}
+	}
+
+	if err := os.WriteFile(a.MarkdownFile, []byte(a.markdownReport(baseline)), 0o644); err != nil { //nolint:gosec,mnd // ok
+		return fmt.Errorf("error writing the Markdown report: %w", err)
+	}
+
+	if !a.Quiet {
+		fmt.Fprintf(a.dumpSink, "Markdown report written: %s\n", a.MarkdownFile) //nolint:errcheck // ok
+	}
+
+	return nil
+}
+
+// markdownReport returns the Markdown coverage report: the total coverage
+// and, when the coverage profile is known, the coverage of each package,
+// both with their change vs the baseline profile, if any.
+func (a app) markdownReport(baseline *badge.Profile) string {
+	var b strings.Builder
+
+	total := a.totalCoverage()
+
+	fmt.Fprintf(&b, "## Coverage report\n\n%s **%.1f%%** of statements", a.emoji(total), total)
+
+	switch {
+	case baseline != nil:
+		fmt.Fprintf(&b, " (%s vs %.1f%% baseline)\n\n", change(total-baseline.Percent()), baseline.Percent())
+	case a.BaselineFile != "":
+		b.WriteString(" (no baseline coverage profile found)\n\n")
+	default:
+		b.WriteString("\n\n")
+	}
+
+	if a.profile == nil {
+		return b.String()
+	}
+
+	b.WriteString("| | Package | Statements | Coverage |")
+
+	if baseline != nil {
+		b.WriteString(" Change |\n| --- | --- | ---: | ---: | ---: |\n")
+	} else {
+		b.WriteString("\n| --- | --- | ---: | ---: |\n")
+	}
+
+	var previous []badge.Coverage
+	if baseline != nil {
+		previous = baseline.Packages()
+	}
+
+	for _, pkg := range a.profile.Packages() {
+		fmt.Fprintf(&b, "| %s | %s | %d/%d | %.1f%% |", a.emoji(pkg.Percent()), pkg.Name, pkg.Covered, pkg.Statements, pkg.Percent())
+
+		if baseline != nil {
+			if i := slices.IndexFunc(previous, func(c badge.Coverage) bool { return c.Name == pkg.Name }); i >= 0 {
+				fmt.Fprintf(&b, " %s |", change(pkg.Percent()-previous[i].Percent()))
+			} else {
+				b.WriteString(" new |")
+			}
+		}
+
+		b.WriteString("\n")
+	}
+
+	current := a.profile.Packages()
+
+	for _, pkg := range previous {
+		if !slices.ContainsFunc(current, func(c badge.Coverage) bool { return c.Name == pkg.Name }) {
+			fmt.Fprintf(&b, "| | %s | | | removed |\n", pkg.Name)
+		}
+	}
+
+	return b.String()
+}
+
+// totalCoverage returns the coverage of the profile, if known, else the given one.
+func (a app) totalCoverage() float64 {
+	if a.profile != nil {
+		return a.profile.Percent()
+	}
+
+	return *a.CoveragePC
+}
+
+// emoji returns the colored circle emoji of the level color of coverage.
+func (a app) emoji(coverage float64) string {
+	return badge.ColorEmoji(a.Levels.ColorFor(coverage, a.ColorMode))
+}
+
+// change formats the given coverage change with an up or down arrow,
+// changes below the displayed precision being no change.
+func change(delta float64) string {
+	switch delta = math.Round(delta*10) / 10; { //nolint:mnd // ok
+	case delta > 0:
+		return fmt.Sprintf("▲ +%.1f%%", delta)
+	case delta < 0:
+		return fmt.Sprintf("▼ %.1f%%", delta)
+	default:
+		return "= 0.0%"
+	}
+}
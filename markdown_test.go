@@ -0,0 +1,126 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alexaandru/stampli/badge"
+)
+
+func TestMarkdownReport(t *testing.T) {
+	t.Parallel()
+
+	current := mustProfile(t, "m/a/a.go:1.1,2.2 8 1\nm/a/a.go:3.1,4.2 2 0\nm/b/b.go:1.1,2.2 5 0\n")
+	baseline := mustProfile(t, "m/a/a.go:1.1,2.2 7 1\nm/a/a.go:3.1,4.2 3 0\nm/c/c.go:1.1,2.2 5 1\n")
+	coverage := 72.5
+
+	tests := []struct {
+		name     string
+		profile  *badge.Profile
+		baseline *badge.Profile
+		file     string
+		want     string
+	}{
+		{
+			name:    "Without baseline",
+			profile: current,
+			want: "## Coverage report\n\n🔴 **53.3%** of statements\n\n| | Package | Statements | Coverage |\n| --- | --- | ---: | ---: |\n" +
+				"| 🟢 | m/a | 8/10 | 80.0% |\n| 🔴 | m/b | 0/5 | 0.0% |\n",
+		},
+		{
+			name:     "With baseline",
+			profile:  current,
+			baseline: baseline,
+			file:     "base.out",
+			want: "## Coverage report\n\n🔴 **53.3%** of statements (▼ -26.7% vs 80.0% baseline)\n\n" +
+				"| | Package | Statements | Coverage | Change |\n| --- | --- | ---: | ---: | ---: |\n" +
+				"| 🟢 | m/a | 8/10 | 80.0% | ▲ +10.0% |\n| 🔴 | m/b | 0/5 | 0.0% | new |\n| | m/c | | | removed |\n",
+		},
+		{
+			name:     "No change",
+			profile:  baseline,
+			baseline: baseline,
+			file:     "base.out",
+			want: "## Coverage report\n\n🟢 **80.0%** of statements (= 0.0% vs 80.0% baseline)\n\n" +
+				"| | Package | Statements | Coverage | Change |\n| --- | --- | ---: | ---: | ---: |\n" +
+				"| 🟢 | m/a | 7/10 | 70.0% | = 0.0% |\n| 🟢 | m/c | 5/5 | 100.0% | = 0.0% |\n",
+		},
+		{
+			name: "Missing baseline",
+			file: "missing.out",
+			want: "## Coverage report\n\n🟢 **72.5%** of statements (no baseline coverage profile found)\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			a := app{Config: badge.Config{CoveragePC: &coverage, BaselineFile: tt.file}, profile: tt.profile}
+			a.Levels = mustLevels(t, "70=#44cc11,=#ff0001")
+
+			if got := a.markdownReport(tt.baseline); got != tt.want {
+				t.Errorf("markdownReport() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	baseline := copyCoverageSample(t, dir)
+	coverage := 93.1
+
+	var output strings.Builder
+
+	a := app{Config: badge.Config{CoveragePC: &coverage, BaselineFile: baseline}, dumpSink: &output}
+	a.MarkdownFile = filepath.Join(dir, "coverage.md")
+	a.Levels = mustLevels(t, "85=#44cc11,=#ff0001")
+
+	if err := a.writeMarkdown(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	got, err := os.ReadFile(a.MarkdownFile)
+	if err != nil {
+		t.Fatalf("Failed to read the report: %v", err)
+	}
+
+	if want := "🟢 **93.1%** of statements (= 0.0% vs 93.1% baseline)"; !strings.Contains(string(got), want) {
+		t.Errorf("Report = %q, want it to contain %q", got, want)
+	}
+
+	if want := "Markdown report written: " + a.MarkdownFile + "\n"; output.String() != want {
+		t.Errorf("Output = %q, want %q", output.String(), want)
+	}
+
+	a.BaselineFile = filepath.Join(dir, "coverage.md") // Not a coverage profile.
+	if err = a.writeMarkdown(); err == nil {
+		t.Error("Expected an error for an invalid baseline")
+	}
+}
+
+func TestChange(t *testing.T) {
+	t.Parallel()
+
+	for delta, want := range map[float64]string{1.26: "▲ +1.3%", -0.5: "▼ -0.5%", 0.04: "= 0.0%", -0.04: "= 0.0%"} {
+		if got := change(delta); got != want {
+			t.Errorf("change(%v) = %q, want %q", delta, got, want)
+		}
+	}
+}
+
+func mustProfile(t *testing.T, blocks string) *badge.Profile {
+	t.Helper()
+
+	p, err := badge.ParseProfile(strings.NewReader("mode: set\n" + blocks))
+	if err != nil {
+		t.Fatalf("Invalid profile: %v", err)
+	}
+
+	return p
+}
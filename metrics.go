@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/alexaandru/stampli/badge"
+)
+
+// writeMetrics writes the coverage metrics to MetricsFile, if set, in the
+// OpenMetrics text format. The file is replaced atomically, so that the
+// node_exporter textfile collector never reads a partial one.
+func (a app) writeMetrics() error {
+	if a.MetricsFile == "" || a.BadgeType == badge.TypeTests {
+		return nil
+	}
+
+	if a.profile == nil {
+		return fmt.Errorf("the metrics %w", errNoCoverageProfile)
+	}
+
+	var buf bytes.Buffer
+
+	if err := a.profile.WriteOpenMetrics(&buf, modulePath("go.mod"), time.Now()); err != nil {
+		return fmt.Errorf("error generating the metrics: %w", err)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(a.MetricsFile), ".stampli-metrics-*")
+	if err != nil {
+		return fmt.Errorf("error writing the metrics: %w", err)
+	}
+
+	defer os.Remove(f.Name()) //nolint:errcheck // already renamed, unless failed
+
+	if _, err = f.Write(buf.Bytes()); err == nil {
+		err = f.Chmod(0o644) //nolint:mnd // readable by the collector
+	}
+
+	if err = errors.Join(err, f.Close()); err == nil {
+		err = os.Rename(f.Name(), a.MetricsFile)
+	}
+
+	if err != nil {
+		return fmt.Errorf("error writing the metrics: %w", err)
+	}
+
+	if !a.Quiet {
+		fmt.Fprintf(a.dumpSink, "Metrics written: %s\n", a.MetricsFile) //nolint:errcheck // ok
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alexaandru/stampli/badge"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	t.Parallel()
+
+	profile, err := badge.ReadProfile(filepath.Join("testdata", "coverage-sample.out"))
+	if err != nil {
+		t.Fatalf("Failed to read testdata coverage file: %v", err)
+	}
+
+	dir := t.TempDir()
+
+	var output strings.Builder
+
+	a := app{Config: badge.Config{MetricsFile: filepath.Join(dir, "stampli.prom")}, profile: profile, dumpSink: &output}
+	if err = a.writeMetrics(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	got, err := os.ReadFile(a.MetricsFile)
+	if err != nil {
+		t.Fatalf("Failed to read the metrics: %v", err)
+	}
+
+	want := `stampli_coverage_percent{module="github.com/alexaandru/stampli",package="github.com/alexaandru/stampli"} 93.10344827586206`
+	if !strings.Contains(string(got), want) {
+		t.Errorf("Metrics should contain %q, got:\n%s", want, got)
+	}
+
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected only the metrics file in %s, got %v", dir, entries)
+	}
+
+	if output.String() != "Metrics written: "+a.MetricsFile+"\n" {
+		t.Errorf("Output = %q", output.String())
+	}
+
+	a.profile = nil
+	if err = a.writeMetrics(); !errors.Is(err, errNoCoverageProfile) {
+		t.Errorf("Expected error %v, got %v", errNoCoverageProfile, err)
+	}
+}
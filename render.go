@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/alexaandru/stampli/badge"
+)
+
+// Render output formats.
+const (
+	formatSVG  = "svg"
+	formatJSON = "json"
+	formatPNG  = "png"
+)
+
+// maxTextLen is the max length (in bytes) of the label and of the unit, as the
+// badge width, and so the PNG image size, grows with it.
+const maxTextLen = 256
+
+var (
+	errInvalidValue  = errors.New("invalid badge value")
+	errUnknownFormat = errors.New("unknown badge format (expected svg, json or png)")
+	errTextTooLong   = fmt.Errorf("badge label or unit too long (max %d bytes)", maxTextLen)
+)
+
+// renderOptions are the options of the value badges, rendered
+// by the render command and by the /badge endpoint of serve.
+type renderOptions struct {
+	label    string
+	value    string
+	unit     string
+	style    string
+	format   string
+	template string // The template source, the built-in one if empty.
+}
+
+func (o *renderOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.label, "label", badge.TypeValue, "Badge label")
+	fs.StringVar(&o.value, "value", "", "Badge value, mapped to the color by the levels (required)")
+	fs.StringVar(&o.unit, "unit", "", "Unit appended to the value, i.e. %")
+	fs.StringVar(&o.style, "style", badge.StyleFlat, "Badge style: flat or flat-square")
+	fs.StringVar(&o.format, "format", formatSVG, "Badge format: svg, json (shields.io endpoint) or png")
+}
+
+// renderValue renders the given value badge, returning it and its content type.
+func (a app) renderValue(o renderOptions, levels badge.Levels) ([]byte, string, error) {
+	if len(o.label) > maxTextLen || len(o.unit) > maxTextLen {
+		return nil, "", errTextTooLong
+	}
+
+	value, err := strconv.ParseFloat(o.value, 64)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %q", errInvalidValue, o.value)
+	}
+
+	opts := badge.Options{
+		Time:       time.Now(),
+		Type:       badge.TypeValue,
+		Label:      o.label,
+		Coverage:   value,
+		Unit:       o.unit,
+		Style:      o.style,
+		Levels:     levels,
+		DarkLevels: a.DarkLevels,
+		ColorMode:  a.ColorMode,
+		Template:   o.template,
+	}
+
+	var body []byte
+
+	switch o.format {
+	case "", formatSVG:
+		body, err = badge.Render(opts)
+		return body, "image/svg+xml", err //nolint:wrapcheck // ok
+	case formatJSON:
+		body, err = badge.RenderEndpoint(opts)
+		return body, "application/json", err //nolint:wrapcheck // ok
+	case formatPNG:
+		body, err = badge.RenderPNG(opts)
+		return body, "image/png", err //nolint:wrapcheck // ok
+	default:
+		return nil, "", fmt.Errorf("%w: %q", errUnknownFormat, o.format)
+	}
+}
+
+// render renders a value badge to stdout, or to -output if given. The
+// configured template is only used if given by -template (or its environment
+// variable), as the configured one is usually a coverage badge one.
+func (a *app) render() error {
+	if slices.Contains(a.overrideKeys, "template") {
+		if err := a.LoadTemplate(); err != nil {
+			return err //nolint:wrapcheck // ok
+		}
+
+		a.renderOpts.template = a.Template
+	}
+
+	body, _, err := a.renderValue(a.renderOpts, a.Levels)
+	if err != nil {
+		return fmt.Errorf("error generating badge: %w", err)
+	}
+
+	if !slices.Contains(a.overrideKeys, "outputFile") {
+		_, err = a.dumpSink.Write(body)
+		return err //nolint:wrapcheck // ok
+	}
+
+	return os.WriteFile(a.OutputFile, body, 0o640) //nolint:wrapcheck,mnd // ok
+}
+
+// serveValue serves the value badge given by the request query parameters:
+// label, value, unit, style, format and levels (in the -levels format).
+func (s *badgeServer) serveValue(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	o := renderOptions{label: q.Get("label"), value: q.Get("value"), unit: q.Get("unit"), style: q.Get("style"), format: q.Get("format")}
+
+	levels := s.app.Levels
+	if q.Has("levels") {
+		levels = nil
+
+		if err := levels.Set(q.Get("levels")); err != nil {
+			http.Error(w, "invalid levels: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	body, contentType, err := s.app.renderValue(o, levels)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Header().Set("ETag", etag(body))
+
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(body))
+}
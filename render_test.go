@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alexaandru/stampli/badge"
+)
+
+func TestRenderCommand(t *testing.T) {
+	t.Parallel()
+
+	output := filepath.Join(t.TempDir(), "e2e.json")
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		file    string
+		wantErr error
+	}{
+		{
+			name: "To stdout",
+			args: []string{"-label", "e2e", "-value", "72.5", "-unit", "%", "-levels", "70=#44cc11,=#ff0001"},
+			want: "<title>e2e: 72.5%</title>",
+		},
+		{
+			name: "To output",
+			args: []string{"-value", "3", "-format", "json", "-levels", "70=#44cc11,=#ff0001", "-output", output},
+			file: output,
+			want: `{"schemaVersion":1,"label":"value","message":"3","color":"ff0001"}`,
+		},
+		{name: "Invalid value", args: []string{"-value", "many"}, wantErr: errInvalidValue},
+		{name: "Unknown format", args: []string{"-value", "1", "-format", "gif"}, wantErr: errUnknownFormat},
+		{name: "Unknown style", args: []string{"-value", "1", "-style", "plastic"}, wantErr: badge.ErrUnknownStyle},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			a, err := newApp(flag.NewFlagSet("test", flag.ContinueOnError), append([]string{"render", "-config", ""}, tt.args...))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var stdout strings.Builder
+
+			a.dumpSink = &stdout
+
+			if err = a.run(); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+
+			got := stdout.String()
+			if tt.file != "" {
+				data, err := os.ReadFile(tt.file)
+				if err != nil {
+					t.Fatalf("Failed to read the badge: %v", err)
+				}
+
+				got = string(data)
+			}
+
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("Badge = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBadgeServerValue(t *testing.T) {
+	t.Parallel()
+
+	a := app{}
+	a.Levels = mustLevels(t, "85=#44cc11,=#ff0001")
+	handler := newBadgeServer(a).handler()
+
+	tests := []struct {
+		query       string
+		code        int
+		contentType string
+		want        string
+	}{
+		{query: "label=e2e&value=72.5&unit=%25", code: http.StatusOK, contentType: "image/svg+xml", want: `<title>e2e: 72.5%</title>`},
+		{query: "label=e2e&value=72.5&levels=70=green,=red", code: http.StatusOK, contentType: "image/svg+xml", want: `fill="#97ca00"`},
+		{query: "value=72.5&style=flat-square", code: http.StatusOK, contentType: "image/svg+xml", want: `rx="0"`},
+		{query: "label=lint&value=0&format=json", code: http.StatusOK, contentType: "application/json", want: `"label":"lint","message":"0"`},
+		{query: "value=1&format=png", code: http.StatusOK, contentType: "image/png", want: "\x89PNG"},
+		{query: "value=1&levels=50=red", code: http.StatusBadRequest, want: "invalid levels"},
+		{query: "value=", code: http.StatusBadRequest, want: "invalid badge value"},
+		{query: "value=1&format=png&label=" + strings.Repeat("x", maxTextLen+1), code: http.StatusBadRequest, want: "too long"},
+		{query: "value=1&unit=" + strings.Repeat("x", maxTextLen+1), code: http.StatusBadRequest, want: "too long"},
+		{query: "value=1&label=" + strings.Repeat("x", maxTextLen), code: http.StatusOK, contentType: "image/svg+xml", want: "<svg"},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/badge?"+tt.query, nil))
+
+		if w.Code != tt.code || !strings.Contains(w.Body.String(), tt.want) {
+			t.Errorf("GET /badge?%s = %d %q, want %d and %q", tt.query, w.Code, w.Body.String(), tt.code, tt.want)
+		}
+
+		if tt.contentType != "" && w.Header().Get("Content-Type") != tt.contentType {
+			t.Errorf("GET /badge?%s Content-Type = %q, want %q", tt.query, w.Header().Get("Content-Type"), tt.contentType)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/alexaandru/stampli/badge"
+)
+
+// stampliRun is the command the scaffolded files run stampli with: the version
+// pinned by the tool directive of go.mod (see go get -tool), so that it runs
+// offline and only changes when upgraded.
+const stampliRun = "go tool stampli"
+
+// scaffoldTemplateFile is the name of the template file written by init,
+// next to the config file.
+const scaffoldTemplateFile = "badge.tmpl"
+
+var errNotGitRoot = errors.New("not a git repository root (no .git directory)")
+
+// makefileTarget is the target added to the Makefile by init -with-makefile.
+const makefileTarget = "coverage-badge"
+
+// initOptions are the flags of the init command, selecting
+// the files to write in addition to the config file.
+type initOptions struct {
+	force    bool
+	template bool
+	makefile bool
+	hook     bool
+	workflow bool
+}
+
+func (o *initOptions) register(fs *flag.FlagSet) {
+	fs.BoolVar(&o.force, "force", false, "Overwrite the existing files")
+	fs.BoolVar(&o.template, "with-template", false, "Also write the default SVG template to "+scaffoldTemplateFile+", referenced by the config")
+	fs.BoolVar(&o.makefile, "with-makefile", false, "Also add a coverage-badge target to the Makefile (appended, if it exists)")
+	fs.BoolVar(&o.hook, "with-hook", false, "Also write a git pre-commit hook regenerating the badge")
+	fs.BoolVar(&o.workflow, "with-workflow", false, "Also write a GitHub Actions workflow regenerating the badge")
+}
+
+// scaffoldFile is a file written by the init command.
+type scaffoldFile struct {
+	path    string
+	content string
+	mode    os.FileMode
+	target  string // The make target appended to the existing file, if any, rather than overwriting it.
+}
+
+//nolint:gochecknoglobals // ok
+var (
+	makefileTmpl = template.Must(template.New("Makefile").Parse(`.PHONY: ` + makefileTarget + `
+
+` + makefileTarget + `:
+	{{.Run}} -quiet
+`))
+
+	hookTmpl = template.Must(template.New("pre-commit").Parse(`#!/bin/sh
+# Regenerates the coverage badge and adds it to the commit.
+{{.Run}} -quiet || exit 1
+git add {{.OutputFile}}
+`))
+
+	workflowTmpl = template.Must(template.New("workflow").Parse(`name: Coverage badge
+on:
+  push:
+    branches:
+      - main
+permissions:
+  contents: write
+jobs:
+  badge:
+    runs-on: ubuntu-latest
+    name: Coverage badge
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/setup-go@v5
+        with:
+          go-version-file: go.mod
+      - name: Generate coverage badge
+        run: {{.Run}} -quiet
+      - name: Commit coverage badge
+        run: |
+          git config user.name github-actions
+          git config user.email github-actions@github.com
+          git add {{.OutputFile}}
+          git commit -m "Update coverage badge" || exit 0
+          git push
+`))
+)
+
+// initConfig writes the default config file and the optional scaffold files,
+// refusing to overwrite any existing one, unless forced. The Makefile target
+// is appended to the existing Makefile instead, unless it already has it.
+func (a *app) initConfig() error {
+	files, err := a.scaffoldFiles()
+	if err != nil {
+		return err
+	}
+
+	if !a.initOpts.force {
+		existing := []string{}
+
+		for _, f := range files {
+			if _, err = os.Stat(f.path); err == nil && f.target == "" {
+				existing = append(existing, f.path)
+			}
+		}
+
+		if len(existing) > 0 {
+			return fmt.Errorf("%w: %s (use -force to overwrite)", os.ErrExist, strings.Join(existing, ", "))
+		}
+	}
+
+	for _, f := range files {
+		msg := "Wrote " + f.path
+
+		if f.target != "" {
+			msg, err = appendScaffoldFile(f)
+		} else {
+			err = writeScaffoldFile(f)
+		}
+
+		if err != nil {
+			return fmt.Errorf("could not write %s: %w", f.path, err)
+		}
+
+		if !a.Quiet {
+			fmt.Fprintln(a.dumpSink, msg) //nolint:errcheck // ok
+		}
+	}
+
+	return nil
+}
+
+// scaffoldFiles returns the files to write, as per the init options.
+func (a *app) scaffoldFiles() (files []scaffoldFile, err error) {
+	configFile := a.defaultConfigFile
+	if len(a.args) > 0 {
+		configFile = a.args[0]
+	}
+
+	dir := filepath.Dir(configFile)
+	overrides := map[string]any{}
+
+	if a.initOpts.template {
+		overrides["template"] = scaffoldTemplateFile
+		files = append(files, scaffoldFile{filepath.Join(dir, scaffoldTemplateFile), badge.DefaultTemplate(""), 0o644, ""})
+	}
+
+	config, err := badge.DefaultConfigWith(badge.FormatOf(configFile), overrides)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // ok
+	}
+
+	files = append([]scaffoldFile{{configFile, config, 0o644, ""}}, files...)
+
+	// The config file directory is taken to be the repository root.
+	data := struct{ Run, OutputFile string }{stampliRun, filepath.ToSlash(a.OutputFile)}
+
+	if a.initOpts.makefile {
+		files = append(files, scaffoldFile{filepath.Join(dir, "Makefile"), execScaffold(makefileTmpl, data), 0o644, makefileTarget})
+	}
+
+	if a.initOpts.hook {
+		if fi, err := os.Stat(filepath.Join(dir, ".git")); err != nil || !fi.IsDir() {
+			return nil, fmt.Errorf("%w: %s", errNotGitRoot, dir)
+		}
+
+		files = append(files, scaffoldFile{filepath.Join(dir, ".git", "hooks", "pre-commit"), execScaffold(hookTmpl, data), 0o755, ""})
+	}
+
+	if a.initOpts.workflow {
+		files = append(files, scaffoldFile{
+			filepath.Join(dir, ".github", "workflows", "coverage-badge.yml"), execScaffold(workflowTmpl, data), 0o644, "",
+		})
+	}
+
+	return files, nil
+}
+
+func execScaffold(tmpl *template.Template, data any) string {
+	var buf bytes.Buffer
+
+	// The templates are static and their data always complete.
+	if err := tmpl.Execute(&buf, data); err != nil {
+		panic(err)
+	}
+
+	return buf.String()
+}
+
+func writeScaffoldFile(f scaffoldFile) error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil { //nolint:mnd // ok
+		return err //nolint:wrapcheck // ok
+	}
+
+	if err := os.WriteFile(f.path, []byte(f.content), f.mode); err != nil {
+		return err //nolint:wrapcheck // ok
+	}
+
+	// WriteFile keeps the mode of existing files.
+	return os.Chmod(f.path, f.mode) //nolint:wrapcheck // ok
+}
+
+// appendScaffoldFile appends the file content to the existing file, unless it
+// already has the file target, writing it if missing. It returns the message
+// to report the outcome with.
+func appendScaffoldFile(f scaffoldFile) (string, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "Wrote " + f.path, writeScaffoldFile(f)
+	} else if err != nil {
+		return "", err //nolint:wrapcheck // ok
+	}
+
+	if regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(f.target) + `\s*:`).Match(data) {
+		return fmt.Sprintf("Kept %s (it already has a %s target)", f.path, f.target), nil
+	}
+
+	content := "\n" + f.content
+	if len(data) > 0 && !bytes.HasSuffix(data, []byte("\n")) {
+		content = "\n" + content
+	}
+
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return "", err //nolint:wrapcheck // ok
+	}
+
+	if _, err = file.WriteString(content); err != nil {
+		file.Close() //nolint:errcheck,gosec // the write error is the relevant one
+
+		return "", err //nolint:wrapcheck // ok
+	}
+
+	return fmt.Sprintf("Added the %s target to %s", f.target, f.path), file.Close() //nolint:wrapcheck // ok
+}
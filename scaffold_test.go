@@ -0,0 +1,139 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInitCommand(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		args     []string
+		existing map[string]string
+		git      bool
+		expected map[string]string // The files written and (part of) their content.
+		wantErr  error
+	}{
+		{
+			name:     "Config only",
+			expected: map[string]string{"stampli.json": `"testCommand": "go test`},
+		},
+		{
+			name:     "YAML config",
+			args:     []string{"stampli.yaml"},
+			expected: map[string]string{"stampli.yaml": "testCommand: go test"},
+		},
+		{
+			name: "Everything",
+			args: []string{"-with-template", "-with-makefile", "-with-hook", "-with-workflow"},
+			git:  true,
+			expected: map[string]string{
+				"stampli.json":                         `"template": "badge.tmpl"`,
+				"badge.tmpl":                           "<svg",
+				"Makefile":                             "coverage-badge:\n\tgo tool stampli -quiet",
+				".git/hooks/pre-commit":                "git add coverage-badge.svg",
+				".github/workflows/coverage-badge.yml": "run: go tool stampli -quiet",
+			},
+		},
+		{
+			name:    "Hook outside of a git repository",
+			args:    []string{"-with-hook"},
+			wantErr: errNotGitRoot,
+		},
+		{
+			name:     "Existing files",
+			args:     []string{"-with-template"},
+			existing: map[string]string{"badge.tmpl": "<svg/>"},
+			wantErr:  os.ErrExist,
+		},
+		{
+			name:     "Existing files forced",
+			args:     []string{"-with-makefile", "-force"},
+			existing: map[string]string{"stampli.json": "{}", "Makefile": "all:\n\tgo build"},
+			expected: map[string]string{"stampli.json": "testCommand", "Makefile": "all:\n\tgo build\n\n.PHONY: coverage-badge\n\ncoverage-badge:"},
+		},
+		{
+			name:     "Existing Makefile",
+			args:     []string{"-with-makefile"},
+			existing: map[string]string{"Makefile": "all:\n\tgo build\n"},
+			expected: map[string]string{"stampli.json": "testCommand", "Makefile": "all:\n\tgo build\n\n.PHONY: coverage-badge\n\ncoverage-badge:"},
+		},
+		{
+			name:     "Existing Makefile target",
+			args:     []string{"-with-makefile"},
+			existing: map[string]string{"Makefile": "coverage-badge:\n\tmake cover\n"},
+			expected: map[string]string{"Makefile": "coverage-badge:\n\tmake cover\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			if tt.git {
+				if err := os.Mkdir(filepath.Join(dir, ".git"), 0o755); err != nil {
+					t.Fatalf("Failed to create .git: %v", err)
+				}
+			}
+
+			for name, content := range tt.existing {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+					t.Fatalf("Failed to create %s: %v", name, err)
+				}
+			}
+
+			// The config file (and so the other files) go in dir.
+			args := []string{"init", "-quiet"}
+			for _, arg := range tt.args {
+				if !strings.HasPrefix(arg, "-") {
+					arg = filepath.Join(dir, arg)
+				}
+
+				args = append(args, arg)
+			}
+
+			if !strings.Contains(strings.Join(tt.args, " "), "stampli.") {
+				args = append(args, filepath.Join(dir, "stampli.json"))
+			}
+
+			a, err := newApp(flag.NewFlagSet("test", flag.ContinueOnError), args)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if err = a.run(); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+
+			for name, want := range tt.expected {
+				data, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil || !strings.Contains(string(data), want) {
+					t.Errorf("%s should contain %q, got %s (%v)", name, want, data, err)
+				}
+			}
+
+			if data, err := os.ReadFile(filepath.Join(dir, "Makefile")); err == nil && strings.Count(string(data), "coverage-badge:") > 1 {
+				t.Errorf("Makefile should have one coverage-badge target, got %s", data)
+			}
+
+			if tt.git {
+				if fi, err := os.Stat(filepath.Join(dir, ".git", "hooks", "pre-commit")); err != nil || fi.Mode().Perm()&0o100 == 0 {
+					t.Errorf("Hook should be executable, got %v (%v)", fi.Mode(), err)
+				}
+			}
+
+			if _, ok := tt.existing["stampli.json"]; tt.wantErr != nil && !ok {
+				if _, err = os.Stat(filepath.Join(dir, "stampli.json")); err == nil {
+					t.Error("No file should be written on errors")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/alexaandru/stampli/badge"
+)
+
+const (
+	defaultAddr     = ":8080"
+	shutdownTimeout = 5 * time.Second
+)
+
+var errServeTestsBadge = errors.New("the tests badge cannot be served (it requires running the tests)")
+
+// badgeServer serves the coverage badge, as SVG, shields.io endpoint JSON
+// and PNG, re-rendering them whenever the coverage profile or the template
+// file change, and the value badges given by the query parameters.
+type badgeServer struct {
+	app app // The configuration, with the (unloaded) template file path.
+
+	mu      sync.Mutex
+	state   string // The state of the inputs the badges were rendered from.
+	modTime time.Time
+	badges  map[string]servedBadge // By path.
+}
+
+type servedBadge struct {
+	contentType string
+	body        []byte
+	etag        string
+}
+
+// serve serves the badges on the -addr address, until interrupted.
+func (a *app) serve() error {
+	if a.BadgeType == badge.TypeTests {
+		return errServeTestsBadge
+	}
+
+	if err := a.Levels.Validate(); err != nil {
+		return fmt.Errorf("invalid levels: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := &http.Server{Addr: a.addr, Handler: newBadgeServer(*a).handler(), ReadHeaderTimeout: 10 * time.Second} //nolint:mnd // ok
+	errs := make(chan error, 1)
+
+	go func() { errs <- srv.ListenAndServe() }()
+
+	if !a.Quiet {
+		fmt.Fprintf(a.dumpSink, "Serving the badges on %s (/badge.svg, /badge.json, /badge.png and /badge?label=...&value=...)\n", a.addr) //nolint:errcheck // ok
+	}
+
+	select {
+	case err := <-errs:
+		return err //nolint:wrapcheck // ok
+	case <-ctx.Done():
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	return srv.Shutdown(ctx) //nolint:wrapcheck // ok
+}
+
+func newBadgeServer(a app) *badgeServer {
+	return &badgeServer{app: a}
+}
+
+func (s *badgeServer) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	for _, path := range []string{"/badge.svg", "/badge.json", "/badge.png"} {
+		mux.HandleFunc("GET "+path, s.serveBadge)
+	}
+
+	mux.HandleFunc("GET /badge", s.serveValue)
+
+	return mux
+}
+
+// serveBadge serves the badge of the request path, with an ETag, so that
+// clients revalidate it (cheaply) on every use.
+func (s *badgeServer) serveBadge(w http.ResponseWriter, r *http.Request) {
+	badges, modTime, err := s.load()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	b, ok := badges[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", b.contentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("ETag", b.etag)
+
+	http.ServeContent(w, r, "", modTime, bytes.NewReader(b.body))
+}
+
+// load returns the badges, rendering them again if their inputs changed.
+func (s *badgeServer) load() (map[string]servedBadge, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, modTime, err := s.inputsState()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	if s.badges != nil && state == s.state {
+		return s.badges, s.modTime, nil
+	}
+
+	badges, err := s.render()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	s.state, s.modTime, s.badges = state, modTime, badges
+
+	return badges, modTime, nil
+}
+
+// inputsState returns the size and modification time of the coverage profile
+// (unless the coverage is given) and of the template file (if any), and the
+// latest modification time.
+func (s *badgeServer) inputsState() (string, time.Time, error) {
+	var (
+		state   strings.Builder
+		modTime time.Time
+	)
+
+	for _, file := range []string{s.coverageFile(), s.app.Template} {
+		if file == "" {
+			continue
+		}
+
+		fi, err := os.Stat(file)
+		if err != nil {
+			if file == s.app.Template {
+				return "", time.Time{}, fmt.Errorf("%w: %w", badge.ErrUnreadableTemplate, err)
+			}
+
+			return "", time.Time{}, missingCoverageFileError(file)
+		}
+
+		fmt.Fprintf(&state, "%s:%d:%d\n", file, fi.Size(), fi.ModTime().UnixNano())
+
+		if fi.ModTime().After(modTime) {
+			modTime = fi.ModTime()
+		}
+	}
+
+	return state.String(), modTime, nil
+}
+
+// coverageFile returns the coverage profile the badges are rendered from,
+// none if the coverage is given.
+func (s *badgeServer) coverageFile() string {
+	if s.app.CoveragePC != nil {
+		return ""
+	}
+
+	return s.app.coverageFile()
+}
+
+func (s *badgeServer) render() (map[string]servedBadge, error) {
+	a := s.app
+
+	if file := s.coverageFile(); file != "" {
+		var err error
+		if a.profile, err = badge.ReadProfile(file); err != nil {
+			return nil, err //nolint:wrapcheck // ok
+		}
+
+		coverage := a.profile.Percent()
+		a.CoveragePC = &coverage
+	}
+
+	if err := a.LoadTemplate(); err != nil {
+		return nil, err //nolint:wrapcheck // ok
+	}
+
+	opts := a.badgeOptions()
+	badges := map[string]servedBadge{}
+
+	for path, format := range map[string]struct {
+		contentType string
+		render      func(badge.Options) ([]byte, error)
+	}{
+		"/badge.svg":  {"image/svg+xml", badge.Render},
+		"/badge.json": {"application/json", badge.RenderEndpoint},
+		"/badge.png":  {"image/png", badge.RenderPNG},
+	} {
+		body, err := format.render(opts)
+		if err != nil {
+			return nil, fmt.Errorf("error generating %s: %w", path, err)
+		}
+
+		badges[path] = servedBadge{contentType: format.contentType, body: body, etag: etag(body)}
+	}
+
+	return badges, nil
+}
+
+// etag returns the (strong) ETag of body.
+func etag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexaandru/stampli/badge"
+)
+
+func TestBadgeServer(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	coverageFile := copyCoverageSample(t, dir)
+
+	a := app{Config: badge.Config{CoverageFile: coverageFile}}
+	a.Levels = mustLevels(t, "85=#44cc11,=#ff0001")
+
+	handler := newBadgeServer(a).handler()
+
+	get := func(path string, header ...string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		for i := 0; i < len(header); i += 2 {
+			r.Header.Set(header[i], header[i+1])
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		return w
+	}
+
+	svg := get("/badge.svg")
+	if svg.Code != http.StatusOK || !strings.Contains(svg.Body.String(), "93.1%") {
+		t.Fatalf("GET /badge.svg = %d %q", svg.Code, svg.Body.String())
+	}
+
+	etag := svg.Header().Get("ETag")
+	for header, want := range map[string]string{"Content-Type": "image/svg+xml", "Cache-Control": "no-cache"} {
+		if got := svg.Header().Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+
+	if w := get("/badge.svg", "If-None-Match", etag); w.Code != http.StatusNotModified {
+		t.Errorf("Revalidation = %d, want %d", w.Code, http.StatusNotModified)
+	}
+
+	want := `{"schemaVersion":1,"label":"coverage","message":"93.1%","color":"44cc11"}`
+	if w := get("/badge.json"); w.Body.String() != want {
+		t.Errorf("GET /badge.json = %q, want %q", w.Body.String(), want)
+	}
+
+	if img, err := png.Decode(get("/badge.png").Body); err != nil || img.Bounds().Dy() != 20 {
+		t.Errorf("GET /badge.png is not a 20px high PNG: %v", err)
+	}
+
+	if w := get("/badge.txt"); w.Code != http.StatusNotFound {
+		t.Errorf("GET /badge.txt = %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	// The badges are rendered again when the profile changes.
+	if err := os.WriteFile(coverageFile, []byte("mode: set\nm/a.go:1.1,2.2 1 0\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(coverageFile, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	if w := get("/badge.svg", "If-None-Match", etag); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "0.0%") {
+		t.Errorf("GET /badge.svg after the profile changed = %d %q", w.Code, w.Body.String())
+	}
+
+	if err := os.Remove(coverageFile); err != nil {
+		t.Fatal(err)
+	}
+
+	if w := get("/badge.svg"); w.Code != http.StatusServiceUnavailable || !strings.Contains(w.Body.String(), "coverage file not found") {
+		t.Errorf("GET /badge.svg without a profile = %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestBadgeServerTemplate(t *testing.T) {
+	t.Parallel()
+
+	template := filepath.Join(t.TempDir(), "badge.tmpl")
+	if err := os.WriteFile(template, []byte("<svg>{{.Value}}</svg>"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	coverage := 72.5
+	a := app{Config: badge.Config{CoveragePC: &coverage, Template: template}}
+	a.Levels = mustLevels(t, "85=#44cc11,=#ff0001")
+
+	w := httptest.NewRecorder()
+	newBadgeServer(a).handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/badge.svg", nil))
+
+	if !bytes.Equal(w.Body.Bytes(), []byte("<svg>72.5%</svg>")) {
+		t.Errorf("GET /badge.svg = %q, want the custom template output", w.Body.String())
+	}
+}
+
+func TestBadgeServerCoverage(t *testing.T) {
+	t.Parallel()
+
+	coverage := 72.5
+	a := app{Config: badge.Config{CoveragePC: &coverage}}
+	a.Levels = mustLevels(t, "85=#44cc11,=#ff0001")
+	handler := newBadgeServer(a).handler()
+
+	for path, contentType := range map[string]string{"/badge.svg": "image/svg+xml", "/badge.json": "application/json", "/badge.png": "image/png"} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if w.Code != http.StatusOK || w.Body.Len() == 0 || w.Header().Get("Content-Type") != contentType || w.Header().Get("ETag") == "" {
+			t.Errorf("GET %s = %d %q (Content-Type %q, ETag %q), want the rendered badge",
+				path, w.Code, w.Body.String(), w.Header().Get("Content-Type"), w.Header().Get("ETag"))
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const defaultWatchInterval = time.Second
+
+var errWatchProfiles = errors.New("-watch cannot be combined with -all-profiles")
+
+// watch runs the command, then runs it again whenever the Go files or the
+// coverage profile change, printing the coverage change, until interrupted.
+// The command errors (i.e. failing tests) are reported, without stopping.
+func (a app) watch() error {
+	if a.allProfiles {
+		return errWatchProfiles
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var previous *float64
+
+	cycle := func() {
+		c := a
+		c.CoveragePC = nil // Measured again on every cycle.
+
+		if err := c.command.run(&c); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+
+		if c.CoveragePC == nil {
+			return
+		}
+
+		if previous != nil && !a.Quiet {
+			fmt.Fprintf(a.dumpSink, "Coverage: %.1f%% (%s since the previous run)\n", *c.CoveragePC, change(*c.CoveragePC-*previous)) //nolint:errcheck // ok
+		}
+
+		previous = c.CoveragePC
+	}
+
+	if !a.Quiet {
+		fmt.Fprintf(a.dumpSink, "Watching the Go files and %s for changes (Ctrl+C to stop)\n", a.coverageFile()) //nolint:errcheck // ok
+	}
+
+	watchLoop(ctx, a.watchInterval, func() string { return watchState(".", a.coverageFile()) }, cycle)
+
+	return nil
+}
+
+// watchLoop calls cycle, then calls it again whenever the state changes and
+// then stays the same for an interval, so that bursts of changes (i.e. saving
+// several files) only trigger one cycle. The changes made by the cycle itself
+// (i.e. to the coverage profile) are ignored.
+func watchLoop(ctx context.Context, interval time.Duration, state func() string, cycle func()) {
+	cycle()
+
+	last, pending, changed := state(), "", false
+	ticker := time.NewTicker(interval)
+
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		switch current := state(); {
+		case current == last:
+			changed = false
+		case !changed || current != pending:
+			pending, changed = current, true
+		default:
+			cycle()
+
+			last, changed = state(), false
+		}
+	}
+}
+
+// watchState returns the size and modification time of the Go files under
+// root (skipping the hidden and vendor directories) and of the given files.
+func watchState(root string, files ...string) string {
+	var state strings.Builder
+
+	add := func(path string, info fs.FileInfo) {
+		fmt.Fprintf(&state, "%s:%d:%d\n", path, info.Size(), info.ModTime().UnixNano())
+	}
+
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error { //nolint:errcheck,gosec // unreadable files are skipped
+		switch {
+		case err != nil:
+			return nil //nolint:nilerr // skipped
+		case d.IsDir():
+			if path != root && (strings.HasPrefix(d.Name(), ".") || d.Name() == "vendor") {
+				return filepath.SkipDir
+			}
+		case strings.HasSuffix(path, ".go"):
+			if info, err := d.Info(); err == nil {
+				add(path, info)
+			}
+		}
+
+		return nil
+	})
+
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			add(file, info)
+		}
+	}
+
+	return state.String()
+}
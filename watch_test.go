@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchLoop(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		states []string // Returned in turn: after the first cycle, on every tick and after every other cycle.
+		cycles int
+	}{
+		{name: "No changes", states: []string{"a", "a", "a"}, cycles: 1},
+		{name: "Change", states: []string{"a", "b", "b", "b", "b"}, cycles: 2},
+		{name: "Debounced changes", states: []string{"a", "b", "c", "d", "d", "d", "d"}, cycles: 2},
+		{name: "Reverted change", states: []string{"a", "b", "a", "a"}, cycles: 1},
+		{name: "Changes by the cycle", states: []string{"a", "b", "b", "c", "c", "c"}, cycles: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(t.Context())
+			defer cancel()
+
+			calls, cycles := 0, 0
+			state := func() string {
+				if calls++; calls >= len(tt.states) {
+					cancel()
+				}
+
+				return tt.states[min(calls, len(tt.states))-1]
+			}
+
+			watchLoop(ctx, time.Millisecond, state, func() { cycles++ })
+
+			if cycles != tt.cycles {
+				t.Errorf("Cycles = %d, want %d", cycles, tt.cycles)
+			}
+		})
+	}
+}
+
+func TestWatchState(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	for _, file := range []string{"a.go", "b.txt", "pkg/c.go", ".git/d.go", "vendor/e.go", "coverage.out"} {
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	state := watchState(dir, filepath.Join(dir, "coverage.out"), filepath.Join(dir, "missing.out"))
+
+	var files []string
+	for _, line := range strings.Split(strings.TrimSpace(state), "\n") {
+		files = append(files, strings.TrimPrefix(strings.SplitN(line, ":", 2)[0], dir+string(filepath.Separator)))
+	}
+
+	if got, want := strings.Join(files, " "), "a.go pkg/c.go coverage.out"; got != want {
+		t.Errorf("Watched files = %q, want %q", got, want)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte("changed"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if watchState(dir, filepath.Join(dir, "coverage.out")) == state {
+		t.Error("Expected the state to change with a.go")
+	}
+}